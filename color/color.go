@@ -2,7 +2,7 @@ package color
 
 import (
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +20,19 @@ const (
 	CrossedOut
 )
 
+// Attributes that turn off a single style, leaving the others in place
+const (
+	ResetBold       Attribute = 22 // also ends Faint
+	ResetItalic     Attribute = 23
+	ResetUnderline  Attribute = 24
+	ResetBlinking   Attribute = 25
+	ResetInverse    Attribute = 27
+	ResetConcealed  Attribute = 28
+	ResetCrossedOut Attribute = 29
+	FgDefault       Attribute = 39 // the terminal's foreground color
+	BgDefault       Attribute = 49 // the terminal's background color
+)
+
 // Foreground colors
 const (
 	FgBlack Attribute = iota + 30
@@ -71,32 +84,76 @@ const (
 // Attribute defines a single SGR Code
 type Attribute int
 
-// NoColor defines if the output is colorized or not
-var NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+// NoColor turns colors off everywhere when set to true, whatever the
+// detected profile. Detection already honors NO_COLOR and TERM=dumb; see
+// DetectProfile.
+var NoColor = false
 
-// Color represents a text color
+// Color represents a text color. The escape sequence that starts it is
+// computed when the color is built, so formatting text only copies strings.
 type Color struct {
 	params []Attribute
+	fg, bg *extColor // RGB or 256-palette colors, see RGB and Ansi256
+	start  [ProfileTrueColor + 1]string
 }
 
 // New creates a new Color instance
 func New(attrs ...Attribute) *Color {
-	c := &Color{params: make([]Attribute, 0)}
-	c.Add(attrs...)
-	return c
+	return new(Color).Add(attrs...)
 }
 
 // Add adds attributes to the color
 func (c *Color) Add(attrs ...Attribute) *Color {
 	c.params = append(c.params, attrs...)
+	c.compile()
 	return c
 }
 
+// compile builds the sequence that starts the color for each profile,
+// combining all parameters into one, such as "\033[1;31m"
+func (c *Color) compile() {
+	for p := Profile16; p <= ProfileTrueColor; p++ {
+		var b strings.Builder
+		b.WriteString("\033[")
+		for i, attr := range c.params {
+			if i > 0 {
+				b.WriteByte(';')
+			}
+			b.WriteString(strconv.Itoa(int(attr)))
+		}
+		for i, e := range [2]*extColor{c.fg, c.bg} {
+			if e == nil {
+				continue
+			}
+			if b.Len() > 2 {
+				b.WriteByte(';')
+			}
+			b.WriteString(e.sgr(p, i == 1))
+		}
+		c.start[p] = ""
+		if b.Len() > 2 {
+			b.WriteByte('m')
+			c.start[p] = b.String()
+		}
+	}
+}
+
 // Sprint formats using the default formats for its operands and returns the resulting string
 func (c *Color) Sprint(args ...interface{}) string {
+	if len(args) == 1 {
+		return c.sprint(args[0])
+	}
 	return c.wrap(fmt.Sprint(args...))
 }
 
+// sprint is Sprint for a single value, without formatting strings
+func (c *Color) sprint(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return c.wrap(s)
+	}
+	return c.wrap(fmt.Sprint(v))
+}
+
 // Sprintf formats according to a format specifier and returns the resulting string
 func (c *Color) Sprintf(format string, args ...interface{}) string {
 	return c.wrap(fmt.Sprintf(format, args...))
@@ -121,155 +178,219 @@ func (c *Color) Format(template string, args ...interface{}) string {
 	return c.wrap(formatString(template, args...))
 }
 
-// wrap wraps the text with color escape codes
+// wrap wraps the text with color escape codes for the default profile
 func (c *Color) wrap(text string) string {
-	if NoColor {
+	return c.wrapProfile(text, DefaultProfile())
+}
+
+// wrapProfile wraps the text with escape codes an output with profile p
+// understands
+func (c *Color) wrapProfile(text string, p Profile) string {
+	if NoColor || p == ProfileNone || c.start[p] == "" {
 		return text
 	}
-
-	format := make([]string, len(c.params))
-	for i, attr := range c.params {
-		format[i] = fmt.Sprintf("\033[%dm", attr)
+	var b strings.Builder
+	b.Grow(len(c.start[p]) + len(text) + len(resetSeq))
+	c.writeStyled(&b, text, p)
+	return b.String()
+}
+
+const resetSeq = "\033[0m"
+
+// writeStyled writes text in the color for profile p to b. The color is
+// written again after each full reset inside text, so a styled string placed
+// inside another keeps the outer style after it ends. A reset at the very
+// end is left alone since the outer reset follows it.
+func (c *Color) writeStyled(b *strings.Builder, text string, p Profile) {
+	start := c.start[p]
+	b.WriteString(start)
+	for {
+		i, n := nextReset(text)
+		if i < 0 || i+n == len(text) {
+			break
+		}
+		b.WriteString(text[:i+n])
+		b.WriteString(start)
+		text = text[i+n:]
 	}
-
-	return strings.Join(format, "") + text + "\033[0m"
+	b.WriteString(text)
+	b.WriteString(resetSeq)
 }
 
+// nextReset returns the position and length of the first "\033[0m" or
+// "\033[m" in text, or -1
+func nextReset(text string) (int, int) {
+	i, n := strings.Index(text, resetSeq), len(resetSeq)
+	if j := strings.Index(text, "\033[m"); j >= 0 && (i < 0 || j < i) {
+		i, n = j, 3
+	}
+	return i, n
+}
+
+// Styles shared by the helper functions. They are never changed, so using
+// them from several goroutines is safe.
+var (
+	styleRed         = New(FgRed)
+	styleGreen       = New(FgGreen)
+	styleBlue        = New(FgBlue)
+	styleYellow      = New(FgYellow)
+	styleMagenta     = New(FgMagenta)
+	styleCyan        = New(FgCyan)
+	styleWhite       = New(FgWhite)
+	styleBlack       = New(FgBlack)
+	styleBoldRed     = New(Bold, FgRed)
+	styleBoldGreen   = New(Bold, FgGreen)
+	styleBoldBlue    = New(Bold, FgBlue)
+	styleBoldYellow  = New(Bold, FgYellow)
+	styleBoldMagenta = New(Bold, FgMagenta)
+	styleBoldCyan    = New(Bold, FgCyan)
+	styleOnRed       = New(BgRed)
+	styleOnGreen     = New(BgGreen)
+	styleOnBlue      = New(BgBlue)
+	styleOnYellow    = New(BgYellow)
+	styleOnMagenta   = New(BgMagenta)
+	styleOnCyan      = New(BgCyan)
+	styleItalicGreen = New(Italic, FgGreen)
+	styleHighlighted = New(Bold, FgBlack, BgYellow)
+	styleError       = New(Bold, FgRed, BgHiWhite)
+)
+
 // Simple color functions that work with any type - perfect for fmt.Print, fmt.Println, fmt.Printf
 func Red(v interface{}) string {
-	return New(FgRed).Sprint(v)
+	return styleRed.sprint(v)
 }
 
 func Green(v interface{}) string {
-	return New(FgGreen).Sprint(v)
+	return styleGreen.sprint(v)
 }
 
 func Blue(v interface{}) string {
-	return New(FgBlue).Sprint(v)
+	return styleBlue.sprint(v)
 }
 
 func Yellow(v interface{}) string {
-	return New(FgYellow).Sprint(v)
+	return styleYellow.sprint(v)
 }
 
 func Magenta(v interface{}) string {
-	return New(FgMagenta).Sprint(v)
+	return styleMagenta.sprint(v)
 }
 
 func Cyan(v interface{}) string {
-	return New(FgCyan).Sprint(v)
+	return styleCyan.sprint(v)
 }
 
 func White(v interface{}) string {
-	return New(FgWhite).Sprint(v)
+	return styleWhite.sprint(v)
 }
 
 func Black(v interface{}) string {
-	return New(FgBlack).Sprint(v)
+	return styleBlack.sprint(v)
 }
 
 // Bold color functions
 func BoldRed(v interface{}) string {
-	return New(Bold, FgRed).Sprint(v)
+	return styleBoldRed.sprint(v)
 }
 
 func BoldGreen(v interface{}) string {
-	return New(Bold, FgGreen).Sprint(v)
+	return styleBoldGreen.sprint(v)
 }
 
 func BoldBlue(v interface{}) string {
-	return New(Bold, FgBlue).Sprint(v)
+	return styleBoldBlue.sprint(v)
 }
 
 func BoldYellow(v interface{}) string {
-	return New(Bold, FgYellow).Sprint(v)
+	return styleBoldYellow.sprint(v)
 }
 
 func BoldMagenta(v interface{}) string {
-	return New(Bold, FgMagenta).Sprint(v)
+	return styleBoldMagenta.sprint(v)
 }
 
 func BoldCyan(v interface{}) string {
-	return New(Bold, FgCyan).Sprint(v)
+	return styleBoldCyan.sprint(v)
 }
 
 // Background color functions
 func OnRed(v interface{}) string {
-	return New(BgRed).Sprint(v)
+	return styleOnRed.sprint(v)
 }
 
 func OnGreen(v interface{}) string {
-	return New(BgGreen).Sprint(v)
+	return styleOnGreen.sprint(v)
 }
 
 func OnBlue(v interface{}) string {
-	return New(BgBlue).Sprint(v)
+	return styleOnBlue.sprint(v)
 }
 
 func OnYellow(v interface{}) string {
-	return New(BgYellow).Sprint(v)
+	return styleOnYellow.sprint(v)
 }
 
 func OnMagenta(v interface{}) string {
-	return New(BgMagenta).Sprint(v)
+	return styleOnMagenta.sprint(v)
 }
 
 func OnCyan(v interface{}) string {
-	return New(BgCyan).Sprint(v)
+	return styleOnCyan.sprint(v)
 }
 
 // Text color functions (legacy - for backward compatibility)
 func RedText(template string, args ...interface{}) string {
-	return New(FgRed).Format(template, args...)
+	return styleRed.Format(template, args...)
 }
 
 func GreenText(template string, args ...interface{}) string {
-	return New(FgGreen).Format(template, args...)
+	return styleGreen.Format(template, args...)
 }
 
 func BlueText(template string, args ...interface{}) string {
-	return New(FgBlue).Format(template, args...)
+	return styleBlue.Format(template, args...)
 }
 
 func YellowText(template string, args ...interface{}) string {
-	return New(FgYellow).Format(template, args...)
+	return styleYellow.Format(template, args...)
 }
 
 func MagentaText(template string, args ...interface{}) string {
-	return New(FgMagenta).Format(template, args...)
+	return styleMagenta.Format(template, args...)
 }
 
 func CyanText(template string, args ...interface{}) string {
-	return New(FgCyan).Format(template, args...)
+	return styleCyan.Format(template, args...)
 }
 
 // Style combinations
 func BoldRedText(template string, args ...interface{}) string {
-	return New(Bold, FgRed).Format(template, args...)
+	return styleBoldRed.Format(template, args...)
 }
 
 func ItalicGreenText(template string, args ...interface{}) string {
-	return New(Italic, FgGreen).Format(template, args...)
+	return styleItalicGreen.Format(template, args...)
 }
 
 func HighlightedText(template string, args ...interface{}) string {
-	return New(Bold, FgBlack, BgYellow).Format(template, args...)
+	return styleHighlighted.Format(template, args...)
 }
 
+// The semantic functions use the current theme; see SetTheme
 func ErrorText(template string, args ...interface{}) string {
-	return New(Bold, FgRed, BgHiWhite).Format(template, args...)
+	return themed("error").Format(template, args...)
 }
 
 func SuccessText(template string, args ...interface{}) string {
-	return New(Bold, FgGreen).Format(template, args...)
+	return themed("success").Format(template, args...)
 }
 
 func InfoText(template string, args ...interface{}) string {
-	return New(FgCyan).Format(template, args...)
+	return themed("info").Format(template, args...)
 }
 
 func WarningText(template string, args ...interface{}) string {
-	return New(Bold, FgYellow).Format(template, args...)
+	return themed("warning").Format(template, args...)
 }
 
 // Get returns a new Color instance
@@ -278,31 +399,31 @@ func Get() *Color {
 }
 
 // Short form methods
-func (c *Color) R(s string) string { return New(FgRed).Sprint(s) }
-func (c *Color) G(s string) string { return New(FgGreen).Sprint(s) }
-func (c *Color) B(s string) string { return New(FgBlue).Sprint(s) }
-func (c *Color) Y(s string) string { return New(FgYellow).Sprint(s) }
-func (c *Color) M(s string) string { return New(FgMagenta).Sprint(s) }
-func (c *Color) C(s string) string { return New(FgCyan).Sprint(s) }
+func (c *Color) R(s string) string { return styleRed.wrap(s) }
+func (c *Color) G(s string) string { return styleGreen.wrap(s) }
+func (c *Color) B(s string) string { return styleBlue.wrap(s) }
+func (c *Color) Y(s string) string { return styleYellow.wrap(s) }
+func (c *Color) M(s string) string { return styleMagenta.wrap(s) }
+func (c *Color) C(s string) string { return styleCyan.wrap(s) }
 
 // Bold versions
-func (c *Color) BR(s string) string { return New(Bold, FgRed).Sprint(s) }
-func (c *Color) BG(s string) string { return New(Bold, FgGreen).Sprint(s) }
-func (c *Color) BB(s string) string { return New(Bold, FgBlue).Sprint(s) }
-func (c *Color) BY(s string) string { return New(Bold, FgYellow).Sprint(s) }
-func (c *Color) BM(s string) string { return New(Bold, FgMagenta).Sprint(s) }
-func (c *Color) BC(s string) string { return New(Bold, FgCyan).Sprint(s) }
+func (c *Color) BR(s string) string { return styleBoldRed.wrap(s) }
+func (c *Color) BG(s string) string { return styleBoldGreen.wrap(s) }
+func (c *Color) BB(s string) string { return styleBoldBlue.wrap(s) }
+func (c *Color) BY(s string) string { return styleBoldYellow.wrap(s) }
+func (c *Color) BM(s string) string { return styleBoldMagenta.wrap(s) }
+func (c *Color) BC(s string) string { return styleBoldCyan.wrap(s) }
 
 // Background versions
-func (c *Color) OnR(s string) string { return New(BgRed).Sprint(s) }
-func (c *Color) OnG(s string) string { return New(BgGreen).Sprint(s) }
-func (c *Color) OnB(s string) string { return New(BgBlue).Sprint(s) }
-func (c *Color) OnY(s string) string { return New(BgYellow).Sprint(s) }
-func (c *Color) OnM(s string) string { return New(BgMagenta).Sprint(s) }
-func (c *Color) OnC(s string) string { return New(BgCyan).Sprint(s) }
-
-// Semantic helpers
-func (c *Color) E(s string, args ...interface{}) string { return New(FgRed).Format(s, args...) }    // Error
-func (c *Color) S(s string, args ...interface{}) string { return New(FgGreen).Format(s, args...) }  // Success
-func (c *Color) I(s string, args ...interface{}) string { return New(FgBlue).Format(s, args...) }   // Info
-func (c *Color) W(s string, args ...interface{}) string { return New(FgYellow).Format(s, args...) } // Warning
+func (c *Color) OnR(s string) string { return styleOnRed.wrap(s) }
+func (c *Color) OnG(s string) string { return styleOnGreen.wrap(s) }
+func (c *Color) OnB(s string) string { return styleOnBlue.wrap(s) }
+func (c *Color) OnY(s string) string { return styleOnYellow.wrap(s) }
+func (c *Color) OnM(s string) string { return styleOnMagenta.wrap(s) }
+func (c *Color) OnC(s string) string { return styleOnCyan.wrap(s) }
+
+// Semantic helpers, styled by the current theme
+func (c *Color) E(s string, args ...interface{}) string { return themed("error").Format(s, args...) }   // Error
+func (c *Color) S(s string, args ...interface{}) string { return themed("success").Format(s, args...) } // Success
+func (c *Color) I(s string, args ...interface{}) string { return themed("info").Format(s, args...) }    // Info
+func (c *Color) W(s string, args ...interface{}) string { return themed("warning").Format(s, args...) } // Warning
@@ -0,0 +1,194 @@
+package color
+
+import "math"
+
+// Fg returns a color with c as the foreground
+func (c RGBColor) Fg() *Color {
+	return New().AddRGB(c.R, c.G, c.B)
+}
+
+// Bg returns a color with c as the background
+func (c RGBColor) Bg() *Color {
+	return New().AddBgRGB(c.R, c.G, c.B)
+}
+
+// HSL returns the hue in degrees and the saturation and lightness from 0
+// to 1
+func (c RGBColor) HSL() (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (hi + lo) / 2
+	chroma := hi - lo
+	if chroma == 0 {
+		return 0, 0, l
+	}
+	s = chroma / (1 - math.Abs(2*l-1))
+	return hue(r, g, b, hi, chroma), s, l
+}
+
+// HSV returns the hue in degrees and the saturation and value from 0 to 1
+func (c RGBColor) HSV() (h, s, v float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	chroma := hi - lo
+	if chroma == 0 {
+		return 0, 0, hi
+	}
+	return hue(r, g, b, hi, chroma), chroma / hi, hi
+}
+
+// hue returns the hue in degrees of the channels r, g and b with the given
+// maximum and chroma
+func hue(r, g, b, hi, chroma float64) float64 {
+	var h float64
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/chroma, 6)
+	case g:
+		h = (b-r)/chroma + 2
+	default:
+		h = (r-g)/chroma + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// Lab returns the color in CIELAB space with a D65 white point: lightness
+// from 0 to 100 and the a and b axes
+func (c RGBColor) Lab() (l, a, b float64) {
+	v := toLab(c)
+	return v.L, v.A, v.B
+}
+
+// FromHSL returns the color with hue h in degrees and saturation and
+// lightness from 0 to 1
+func FromHSL(h, s, l float64) RGBColor {
+	return hslToRGB(h, s, l)
+}
+
+// FromHSV returns the color with hue h in degrees and saturation and value
+// from 0 to 1
+func FromHSV(h, s, v float64) RGBColor {
+	s = clamp01(s)
+	v = clamp01(v)
+	// HSV and HSL share the hue; convert the other two
+	l := v * (1 - s/2)
+	sl := 0.0
+	if l > 0 && l < 1 {
+		sl = (v - l) / math.Min(l, 1-l)
+	}
+	return hslToRGB(h, sl, l)
+}
+
+// FromLab returns the sRGB color closest to the CIELAB color l, a, b.
+// Colors outside the sRGB gamut are clipped.
+func FromLab(l, a, b float64) RGBColor {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+	inv := func(t float64) float64 {
+		if t*t*t > 216.0/24389 {
+			return t * t * t
+		}
+		return (116*t - 16) * 27 / 24389
+	}
+	x, y, z := inv(fx)*0.95047, inv(fy), inv(fz)*1.08883
+
+	r := 3.2404542*x - 1.5371385*y - 0.4985314*z
+	g := -0.9692660*x + 1.8760108*y + 0.0415560*z
+	bl := 0.0556434*x - 0.2040259*y + 1.0572252*z
+	gamma := func(v float64) uint8 {
+		v = clamp01(v)
+		if v <= 0.0031308 {
+			v *= 12.92
+		} else {
+			v = 1.055*math.Pow(v, 1/2.4) - 0.055
+		}
+		return uint8(math.Round(clamp01(v) * 255))
+	}
+	return RGBColor{gamma(r), gamma(g), gamma(bl)}
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// Lighten returns c with its HSL lightness raised by amount, from 0 to 1
+//
+//	hover := color.Named("steelblue").Lighten(0.1)
+func (c RGBColor) Lighten(amount float64) RGBColor {
+	h, s, l := c.HSL()
+	return hslToRGB(h, s, l+amount)
+}
+
+// Darken returns c with its HSL lightness lowered by amount, from 0 to 1
+func (c RGBColor) Darken(amount float64) RGBColor {
+	return c.Lighten(-amount)
+}
+
+// Saturate returns c with its HSL saturation raised by amount, from 0 to 1.
+// A negative amount desaturates.
+func (c RGBColor) Saturate(amount float64) RGBColor {
+	h, s, l := c.HSL()
+	return hslToRGB(h, s+amount, l)
+}
+
+// Mix blends c with other. A weight of 0 gives c, 1 gives other and 0.5
+// an even mix.
+func (c RGBColor) Mix(other RGBColor, weight float64) RGBColor {
+	w := clamp01(weight)
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a)*(1-w) + float64(b)*w))
+	}
+	return RGBColor{mix(c.R, other.R), mix(c.G, other.G), mix(c.B, other.B)}
+}
+
+// Complement returns the color opposite c on the color wheel
+func (c RGBColor) Complement() RGBColor {
+	h, s, l := c.HSL()
+	return hslToRGB(h+180, s, l)
+}
+
+// Invert returns the negative of c
+func (c RGBColor) Invert() RGBColor {
+	return RGBColor{255 - c.R, 255 - c.G, 255 - c.B}
+}
+
+// Luminance returns the WCAG relative luminance of c, from 0 for black to
+// 1 for white
+func (c RGBColor) Luminance() float64 {
+	return 0.2126*linearize(c.R) + 0.7152*linearize(c.G) + 0.0722*linearize(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 for
+// equal colors to 21 for black on white. WCAG AA asks for at least 4.5 for
+// body text and 3 for large text.
+func ContrastRatio(a, b RGBColor) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// BestForeground returns the candidate with the highest contrast against
+// bg. Without candidates it chooses between black and white.
+//
+//	bg := color.Named("gold")
+//	fg := color.BestForeground(bg)
+//	fmt.Println(bg.Bg().AddRGB(fg.R, fg.G, fg.B).Sprint(" label "))
+func BestForeground(bg RGBColor, candidates ...RGBColor) RGBColor {
+	if len(candidates) == 0 {
+		candidates = []RGBColor{{0, 0, 0}, {255, 255, 255}}
+	}
+	best, bestRatio := candidates[0], -1.0
+	for _, c := range candidates {
+		if r := ContrastRatio(c, bg); r > bestRatio {
+			best, bestRatio = c, r
+		}
+	}
+	return best
+}
@@ -0,0 +1,243 @@
+package color
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tagAttributes are the attribute words of markup tags
+var tagAttributes = map[string]Attribute{
+	"bold":          Bold,
+	"dim":           Faint,
+	"faint":         Faint,
+	"italic":        Italic,
+	"underline":     Underline,
+	"blink":         BlinkSlow,
+	"reverse":       Inverse,
+	"inverse":       Inverse,
+	"hidden":        Concealed,
+	"strike":        CrossedOut,
+	"strikethrough": CrossedOut,
+}
+
+// basicColors are the color names of markup tags that select one of the 8
+// basic terminal colors; with a "bright_" prefix they select the bright one
+var basicColors = map[string]Attribute{
+	"black": 0, "red": 1, "green": 2, "yellow": 3,
+	"blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+}
+
+// semanticStyle returns the style of a semantic tag from the current theme
+func semanticStyle(name string) (*Color, bool) {
+	return CurrentTheme().Style(name)
+}
+
+// Render formats markup with style tags and {placeholders}:
+//
+//	s, err := color.Render("[bold red]Error:[/] cannot open [cyan]{path}[/]", path)
+//
+// A tag lists attributes (bold, dim, italic, underline, blink, reverse,
+// hidden, strike), a foreground color and "on" followed by a background
+// color. Colors are the basic names such as red or bright_red, CSS names
+// such as coral, or hex values such as #ff8800. The semantic tags [error],
+// [warning], [success], [info], [muted], [accent], [heading] and [code] use
+// the current theme.
+//
+// [/] closes the most recent tag and [/name] the open tag written as
+// [name]; tags still open at the end are closed. Tags nest, so
+// "[on blue]a [red]b[/] c[/]" keeps the blue background on " c". Write \[
+// for a literal bracket. Placeholders are filled in order like Format, and
+// the values are never read as markup. Placeholders take no format spec:
+// {total:.2f} is an error, so format such values before passing them.
+// Unknown tags are an error.
+func Render(markup string, args ...interface{}) (string, error) {
+	return renderProfile(markup, DefaultProfile(), args)
+}
+
+// MustRender is like Render but panics if the markup is invalid. It is
+// meant for markup written in the program itself.
+func MustRender(markup string, args ...interface{}) string {
+	s, err := Render(markup, args...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// markupFrame is an open tag with the style in effect inside it
+type markupFrame struct {
+	tag   string
+	style *Color
+}
+
+func renderProfile(markup string, p Profile, args []interface{}) (string, error) {
+	var out, run strings.Builder
+	var stack []markupFrame
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if len(stack) == 0 {
+			out.WriteString(run.String())
+		} else {
+			out.WriteString(stack[len(stack)-1].style.wrapProfile(run.String(), p))
+		}
+		run.Reset()
+	}
+
+	for i := 0; i < len(markup); i++ {
+		switch ch := markup[i]; {
+		case ch == '\\' && i+1 < len(markup) && markup[i+1] == '[':
+			run.WriteByte('[')
+			i++
+		case ch == '[':
+			end := strings.IndexByte(markup[i:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("color: unclosed tag at offset %d (write \\[ for a literal bracket)", i)
+			}
+			tag := strings.Join(strings.Fields(strings.ToLower(markup[i+1:i+end])), " ")
+			flush()
+			var err error
+			if stack, err = applyTag(stack, tag); err != nil {
+				return "", err
+			}
+			i += end
+		case ch == '{' && len(args) > 0 && strings.IndexByte(markup[i:], '}') > 0:
+			end := strings.IndexByte(markup[i:], '}')
+			if name, spec, ok := strings.Cut(markup[i+1:i+end], ":"); ok {
+				return "", fmt.Errorf("color: placeholder {%s:%s} has a format spec; format the value before passing it", name, spec)
+			}
+			run.WriteString(fmt.Sprint(args[0]))
+			args = args[1:]
+			i += end
+		default:
+			run.WriteByte(ch)
+		}
+	}
+	flush()
+	return out.String(), nil
+}
+
+// applyTag opens or closes tag on the stack of open tags
+func applyTag(stack []markupFrame, tag string) ([]markupFrame, error) {
+	if name, ok := strings.CutPrefix(tag, "/"); ok {
+		name = strings.TrimSpace(name)
+		for i := len(stack) - 1; i >= 0; i-- {
+			if name == "" || stack[i].tag == name {
+				return stack[:i], nil
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("color: [/] without an open tag")
+		}
+		return nil, fmt.Errorf("color: [/%s] without an open [%s]", name, name)
+	}
+
+	style, ok := semanticStyle(tag)
+	if !ok {
+		var err error
+		if style, err = parseStyle(tag); err != nil {
+			return nil, err
+		}
+	}
+	if len(stack) > 0 {
+		style = stack[len(stack)-1].style.merge(style)
+	}
+	return append(stack, markupFrame{tag: tag, style: style}), nil
+}
+
+// parseStyle returns the style described by the words of a tag, such as
+// "bold red on white"
+func parseStyle(tag string) (*Color, error) {
+	if tag == "" {
+		return nil, fmt.Errorf("color: empty tag []")
+	}
+
+	c := New()
+	words := strings.Fields(tag)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if attr, ok := tagAttributes[word]; ok {
+			c.Add(attr)
+			continue
+		}
+		background := word == "on"
+		if background {
+			if i+1 == len(words) {
+				return nil, fmt.Errorf("color: missing color after \"on\" in tag [%s]", tag)
+			}
+			i++
+			word = words[i]
+		}
+		if !c.addTagColor(word, background) {
+			return nil, fmt.Errorf("color: unknown style %q in tag [%s]", word, tag)
+		}
+	}
+	return c, nil
+}
+
+// addTagColor sets the foreground or background to the color called name
+// and reports whether the name is known
+func (c *Color) addTagColor(name string, background bool) bool {
+	base := FgBlack
+	if background {
+		base = BgBlack
+	}
+	basic, bright := name, false
+	if rest, ok := strings.CutPrefix(name, "bright_"); ok {
+		basic, bright = rest, true
+	}
+	if n, ok := basicColors[basic]; ok {
+		if bright {
+			n += FgHiBlack - FgBlack
+		}
+		c.Add(base + n)
+		return true
+	}
+
+	var rgb RGBColor
+	var err error
+	if strings.HasPrefix(name, "#") {
+		rgb, err = ParseHex(name)
+	} else if named, ok := LookupNamed(name); ok {
+		rgb = named
+	} else {
+		return false
+	}
+	if err != nil {
+		return false
+	}
+	if background {
+		c.AddBgRGB(rgb.R, rgb.G, rgb.B)
+	} else {
+		c.AddRGB(rgb.R, rgb.G, rgb.B)
+	}
+	return true
+}
+
+// merge returns a new color with the style of c changed by o, as for a tag
+// nested inside another. Colors in o replace those of c.
+func (c *Color) merge(o *Color) *Color {
+	m := &Color{params: append(append([]Attribute(nil), c.params...), o.params...), fg: c.fg, bg: c.bg}
+	for _, attr := range o.params {
+		switch {
+		case isForeground(attr):
+			m.fg = nil
+		case isForeground(attr - 10):
+			m.bg = nil
+		}
+	}
+	if o.fg != nil {
+		m.fg = o.fg
+	}
+	if o.bg != nil {
+		m.bg = o.bg
+	}
+	m.compile()
+	return m
+}
+
+// isForeground reports whether attr selects a basic foreground color
+func isForeground(attr Attribute) bool {
+	return attr >= FgBlack && attr <= FgWhite || attr >= FgHiBlack && attr <= FgHiWhite || attr == FgDefault
+}
@@ -0,0 +1,299 @@
+package color
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ColorMode tells how a ColorValue is given
+type ColorMode int
+
+const (
+	ColorDefault ColorMode = iota // the terminal's own color
+	ColorBasic                    // Index 0-7, or 8-15 for the bright colors
+	ColorPalette                  // Index of the 256-color palette
+	ColorRGB                      // RGB
+)
+
+// ColorValue is the foreground or background of a Style
+type ColorValue struct {
+	Mode  ColorMode
+	Index uint8
+	RGB   RGBColor
+}
+
+// Value returns the RGB value of the color using the xterm palette. The
+// default color has none and gives black.
+func (v ColorValue) Value() RGBColor {
+	switch v.Mode {
+	case ColorBasic, ColorPalette:
+		return palette256(int(v.Index))
+	case ColorRGB:
+		return v.RGB
+	}
+	return RGBColor{}
+}
+
+// Style is the appearance of a span of text. The zero Style is plain text.
+type Style struct {
+	Bold, Faint, Italic, Underline, Blink, Inverse, Concealed, CrossedOut bool
+
+	Fg, Bg ColorValue
+	Link   string // target of an OSC 8 hyperlink
+}
+
+// Span is a run of text in one style
+type Span struct {
+	Text  string
+	Style Style
+}
+
+// Parse splits s into spans of text with the style set by its SGR
+// sequences, including 256-color and truecolor parameters and OSC 8 links.
+// Other escape sequences are dropped. Malformed or unfinished sequences
+// and unknown parameters are ignored rather than treated as errors, so any
+// string can be parsed. Neighboring text in the same style forms one span.
+//
+//	for _, span := range color.Parse(color.Red("a") + "b") {
+//	    fmt.Println(span.Text, span.Style.Fg)
+//	}
+func Parse(s string) []Span {
+	var spans []Span
+	var style Style
+	add := func(text string) {
+		if text == "" {
+			return
+		}
+		if n := len(spans); n > 0 && spans[n-1].Style == style {
+			spans[n-1].Text += text
+			return
+		}
+		spans = append(spans, Span{Text: text, Style: style})
+	}
+
+	for {
+		i := strings.IndexByte(s, '\x1b')
+		if i < 0 {
+			add(s)
+			return spans
+		}
+		add(s[:i])
+		n := escapeLen(s[i:])
+		seq := s[i : i+n]
+		switch {
+		case isSGR(seq):
+			style.applySGR(seq[2 : len(seq)-1])
+		case strings.HasPrefix(seq, "\x1b]8;"):
+			style.Link = linkTarget(seq)
+		}
+		s = s[i+n:]
+	}
+}
+
+// applySGR updates the style with the parameters of an SGR sequence
+func (st *Style) applySGR(params string) {
+	if params == "" {
+		st.reset()
+		return
+	}
+	list := strings.Split(params, ";")
+	for i := 0; i < len(list); i++ {
+		// Parameters may carry sub-parameters after colons, as in 4:3 or
+		// 38:2::255:0:0
+		sub := strings.Split(list[i], ":")
+		code, err := strconv.Atoi(sub[0])
+		if sub[0] == "" {
+			code, err = 0, nil
+		}
+		if err != nil {
+			continue
+		}
+		switch {
+		case code == 0:
+			st.reset()
+		case code == 1:
+			st.Bold = true
+		case code == 2:
+			st.Faint = true
+		case code == 3:
+			st.Italic = true
+		case code == 4:
+			st.Underline = len(sub) == 1 || sub[1] != "0"
+		case code == 5 || code == 6:
+			st.Blink = true
+		case code == 7:
+			st.Inverse = true
+		case code == 8:
+			st.Concealed = true
+		case code == 9:
+			st.CrossedOut = true
+		case code == 21:
+			st.Underline = true // double underline
+		case code == 22:
+			st.Bold, st.Faint = false, false
+		case code == 23:
+			st.Italic = false
+		case code == 24:
+			st.Underline = false
+		case code == 25:
+			st.Blink = false
+		case code == 27:
+			st.Inverse = false
+		case code == 28:
+			st.Concealed = false
+		case code == 29:
+			st.CrossedOut = false
+		case code >= 30 && code <= 37:
+			st.Fg = ColorValue{Mode: ColorBasic, Index: uint8(code - 30)}
+		case code >= 90 && code <= 97:
+			st.Fg = ColorValue{Mode: ColorBasic, Index: uint8(code - 90 + 8)}
+		case code >= 40 && code <= 47:
+			st.Bg = ColorValue{Mode: ColorBasic, Index: uint8(code - 40)}
+		case code >= 100 && code <= 107:
+			st.Bg = ColorValue{Mode: ColorBasic, Index: uint8(code - 100 + 8)}
+		case code == 39:
+			st.Fg = ColorValue{}
+		case code == 49:
+			st.Bg = ColorValue{}
+		case code == 38 || code == 48:
+			var v ColorValue
+			var ok bool
+			if len(sub) > 1 {
+				v, ok = extendedColor(sub[1:], true)
+			} else {
+				var used int
+				v, ok, used = extendedParams(list[i+1:])
+				i += used
+			}
+			if !ok {
+				continue
+			}
+			if code == 38 {
+				st.Fg = v
+			} else {
+				st.Bg = v
+			}
+		}
+	}
+}
+
+// reset clears the style except for the link, which SGR does not affect
+func (st *Style) reset() {
+	*st = Style{Link: st.Link}
+}
+
+// extendedParams reads the color after a 38 or 48 written with semicolons,
+// as in 38;5;208 or 38;2;255;136;0, and returns how many parameters it used
+func extendedParams(list []string) (ColorValue, bool, int) {
+	if len(list) == 0 {
+		return ColorValue{}, false, 0
+	}
+	n := 2
+	if list[0] == "2" {
+		n = 4
+	}
+	if len(list) < n {
+		return ColorValue{}, false, len(list)
+	}
+	v, ok := extendedColor(list[:n], false)
+	return v, ok, n
+}
+
+// extendedColor reads the mode and values of an extended color. The colon
+// form of truecolor has a color space ID before the values, which may be
+// empty or left out.
+func extendedColor(values []string, colon bool) (ColorValue, bool) {
+	nums := make([]int, 0, len(values))
+	for _, s := range values {
+		n, err := strconv.Atoi(s)
+		if s == "" {
+			n, err = -1, nil
+		}
+		if err != nil || n > 255 {
+			return ColorValue{}, false
+		}
+		nums = append(nums, n)
+	}
+	switch {
+	case len(nums) == 2 && nums[0] == 5 && nums[1] >= 0:
+		return ColorValue{Mode: ColorPalette, Index: uint8(nums[1])}, true
+	case len(nums) >= 4 && nums[0] == 2:
+		rgb := nums[1:]
+		if colon && len(rgb) == 4 {
+			rgb = rgb[1:] // color space ID
+		}
+		if len(rgb) != 3 || rgb[0] < 0 || rgb[1] < 0 || rgb[2] < 0 {
+			return ColorValue{}, false
+		}
+		return ColorValue{Mode: ColorRGB, RGB: RGBColor{uint8(rgb[0]), uint8(rgb[1]), uint8(rgb[2])}}, true
+	}
+	return ColorValue{}, false
+}
+
+// Color returns a Color that writes text in the style. The link is not
+// part of it.
+func (st Style) Color() *Color {
+	c := &Color{}
+	for _, attr := range []struct {
+		on   bool
+		attr Attribute
+	}{
+		{st.Bold, Bold}, {st.Faint, Faint}, {st.Italic, Italic}, {st.Underline, Underline},
+		{st.Blink, BlinkSlow}, {st.Inverse, Inverse}, {st.Concealed, Concealed}, {st.CrossedOut, CrossedOut},
+	} {
+		if attr.on {
+			c.params = append(c.params, attr.attr)
+		}
+	}
+	c.fg = st.Fg.ext(&c.params, FgBlack, FgHiBlack)
+	c.bg = st.Bg.ext(&c.params, BgBlack, BgHiBlack)
+	c.compile()
+	return c
+}
+
+// ext returns v as an extended color, or adds it to params when it is a
+// basic color
+func (v ColorValue) ext(params *[]Attribute, base, bright Attribute) *extColor {
+	switch v.Mode {
+	case ColorBasic:
+		if v.Index < 8 {
+			*params = append(*params, base+Attribute(v.Index))
+		} else {
+			*params = append(*params, bright+Attribute(v.Index-8))
+		}
+	case ColorPalette:
+		return &extColor{index: int(v.Index)}
+	case ColorRGB:
+		return &extColor{rgb: v.RGB, index: -1}
+	}
+	return nil
+}
+
+// RenderSpans joins spans back into a string with escape sequences for the
+// default profile, the reverse of Parse. Parsing text and rendering it again
+// converts its colors to what the current output can show.
+func RenderSpans(spans []Span) string {
+	p := DefaultProfile()
+	links := p != ProfileNone && !NoColor // like colors, links are escape codes
+	var b strings.Builder
+	for _, span := range spans {
+		link := links && span.Style.Link != ""
+		if link {
+			b.WriteString("\x1b]8;;" + span.Style.Link + "\x1b\\")
+		}
+		b.WriteString(span.Style.Color().wrapProfile(span.Text, p))
+		if link {
+			b.WriteString("\x1b]8;;\x1b\\")
+		}
+	}
+	return b.String()
+}
+
+// Width returns the number of terminal columns the span's text takes up
+func (s Span) Width() int {
+	width := 0
+	for _, r := range s.Text {
+		width += runeWidth(r)
+	}
+	return width
+}
@@ -0,0 +1,63 @@
+package color
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Fprint formats like fmt.Fprint and writes the result to w in the color.
+// The profile is detected for w, so writing to a file or a pipe leaves the
+// text plain.
+func (c *Color) Fprint(w io.Writer, args ...interface{}) (int, error) {
+	return io.WriteString(w, c.wrapProfile(fmt.Sprint(args...), profileFor(w)))
+}
+
+// Fprintf formats like fmt.Fprintf and writes the result to w in the color
+func (c *Color) Fprintf(w io.Writer, format string, args ...interface{}) (int, error) {
+	return io.WriteString(w, c.wrapProfile(fmt.Sprintf(format, args...), profileFor(w)))
+}
+
+// Fprintln formats like fmt.Fprintln and writes the result to w in the
+// color. The newline comes after the reset, so the color does not spill
+// onto the next line.
+func (c *Color) Fprintln(w io.Writer, args ...interface{}) (int, error) {
+	text := fmt.Sprintln(args...)
+	return io.WriteString(w, c.wrapProfile(text[:len(text)-1], profileFor(w))+"\n")
+}
+
+// Print formats like fmt.Print and writes the result to stdout in the color
+func (c *Color) Print(args ...interface{}) (int, error) {
+	return c.Fprint(os.Stdout, args...)
+}
+
+// Printf formats like fmt.Printf and writes the result to stdout in the
+// color
+func (c *Color) Printf(format string, args ...interface{}) (int, error) {
+	return c.Fprintf(os.Stdout, format, args...)
+}
+
+// Println formats like fmt.Println and writes the result to stdout in the
+// color
+func (c *Color) Println(args ...interface{}) (int, error) {
+	return c.Fprintln(os.Stdout, args...)
+}
+
+// SprintFunc returns Sprint bound to the color, to keep a style as a plain
+// function
+//
+//	warn := color.New(color.Bold, color.FgYellow).SprintFunc()
+//	fmt.Println(warn("disk almost full"))
+func (c *Color) SprintFunc() func(args ...interface{}) string {
+	return c.Sprint
+}
+
+// PrintfFunc returns Printf bound to the color, without its results
+//
+//	logf := color.New(color.FgCyan).PrintfFunc()
+//	logf("%d files copied\n", n)
+func (c *Color) PrintfFunc() func(format string, args ...interface{}) {
+	return func(format string, args ...interface{}) {
+		c.Printf(format, args...)
+	}
+}
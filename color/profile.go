@@ -0,0 +1,156 @@
+package color
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/grandpaej/fmtpy/v2/internal/term"
+)
+
+// Profile is the range of colors an output can show
+type Profile int
+
+const (
+	ProfileNone      Profile = iota // no escape codes at all
+	Profile16                       // the 16 basic and bright colors
+	Profile256                      // the xterm 256-color palette
+	ProfileTrueColor                // 24-bit RGB
+)
+
+func (p Profile) String() string {
+	switch p {
+	case Profile16:
+		return "16"
+	case Profile256:
+		return "256"
+	case ProfileTrueColor:
+		return "truecolor"
+	}
+	return "none"
+}
+
+// DetectProfile returns the profile to use when writing to w. Colors are
+// only used when w is a terminal, unless forced by the environment:
+//
+//   - NO_COLOR (non-empty) turns colors off
+//   - FORCE_COLOR forces colors on: 0 or false means off, 1 or true 16
+//     colors, 2 256 colors and 3 truecolor
+//   - CLICOLOR_FORCE (not 0) forces colors on; CLICOLOR=0 turns them off
+//   - TERM=dumb turns colors off
+//   - COLORTERM=truecolor or 24bit and TERM ending in -truecolor or -direct
+//     select truecolor; TERM containing 256color selects 256 colors
+//
+// Only files can be terminals. On Linux, macOS, FreeBSD, NetBSD and
+// DragonFly the terminal is queried; elsewhere a file counts as one when it
+// is a character device.
+func DetectProfile(w io.Writer) Profile {
+	return detectProfile(os.LookupEnv, isTerminal(w))
+}
+
+// profileFor returns the profile for writing to w: the default profile
+// for stdout, so SetDefaultProfile applies to it, and the detected one for
+// anything else
+func profileFor(w io.Writer) Profile {
+	if f, ok := w.(*os.File); ok && f == os.Stdout {
+		return DefaultProfile()
+	}
+	return DetectProfile(w)
+}
+
+// isTerminal reports whether w writes to a terminal. Where the terminal
+// cannot be queried, a file counts as one when it is a character device,
+// so output redirected to a file or pipe stays plain.
+func isTerminal(w io.Writer) bool {
+	if !term.Supported {
+		f, ok := w.(interface{ Stat() (os.FileInfo, error) })
+		if !ok {
+			return false
+		}
+		info, err := f.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	}
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return term.IsTerminal(f.Fd())
+}
+
+// detectProfile applies the environment rules to a writer that is or is
+// not a terminal
+func detectProfile(lookupEnv func(string) (string, bool), tty bool) Profile {
+	getenv := func(key string) string {
+		v, _ := lookupEnv(key)
+		return v
+	}
+	if getenv("NO_COLOR") != "" {
+		return ProfileNone
+	}
+
+	// FORCE_COLOR with an empty value also forces colors
+	forced := ProfileNone
+	if v, ok := lookupEnv("FORCE_COLOR"); ok {
+		switch strings.ToLower(v) {
+		case "0", "false":
+			return ProfileNone
+		case "2":
+			forced = Profile256
+		case "3":
+			forced = ProfileTrueColor
+		default:
+			forced = Profile16
+		}
+	} else if v := getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		forced = Profile16
+	}
+
+	if forced == ProfileNone {
+		if !tty || getenv("CLICOLOR") == "0" {
+			return ProfileNone
+		}
+	}
+
+	termName := strings.ToLower(getenv("TERM"))
+	env := Profile16
+	switch ct := strings.ToLower(getenv("COLORTERM")); {
+	case termName == "dumb":
+		env = ProfileNone
+	case ct == "truecolor" || ct == "24bit",
+		strings.HasSuffix(termName, "-truecolor"), strings.HasSuffix(termName, "-direct"),
+		getenv("WT_SESSION") != "":
+		env = ProfileTrueColor
+	case strings.Contains(termName, "256color"):
+		env = Profile256
+	}
+	return max(env, forced)
+}
+
+var (
+	profileMu      sync.Mutex
+	defaultProfile *Profile
+)
+
+// DefaultProfile returns the profile used by Sprint and the other string
+// functions, which cannot know where their result will be written. It is
+// detected for stdout the first time it is needed.
+func DefaultProfile() Profile {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	if defaultProfile == nil {
+		p := DetectProfile(os.Stdout)
+		defaultProfile = &p
+	}
+	return *defaultProfile
+}
+
+// SetDefaultProfile replaces the profile used by the string functions and
+// returns the previous one, for example to force colors in tests
+func SetDefaultProfile(p Profile) Profile {
+	prev := DefaultProfile()
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	defaultProfile = &p
+	return prev
+}
@@ -0,0 +1,278 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RGBColor is a 24-bit color
+type RGBColor struct {
+	R, G, B uint8
+}
+
+// extColor is a foreground or background color beyond the basic attributes:
+// either an RGB value or an entry of the 256-color palette
+type extColor struct {
+	rgb   RGBColor
+	index int // palette entry, or -1 for rgb
+}
+
+// RGB returns a color with a 24-bit foreground. On outputs without
+// truecolor it is shown as the nearest 256- or 16-color entry.
+//
+//	fmt.Println(color.RGB(255, 136, 0).Sprint("orange"))
+func RGB(r, g, b uint8) *Color {
+	return New().AddRGB(r, g, b)
+}
+
+// BgRGB returns a color with a 24-bit background
+func BgRGB(r, g, b uint8) *Color {
+	return New().AddBgRGB(r, g, b)
+}
+
+// Hex returns a color with the foreground given as "#ff8800", "ff8800" or
+// "#f80". An invalid value gives a color that leaves text unchanged; use
+// ParseHex to check it.
+func Hex(hex string) *Color {
+	rgb, err := ParseHex(hex)
+	if err != nil {
+		return New()
+	}
+	return New().AddRGB(rgb.R, rgb.G, rgb.B)
+}
+
+// BgHex returns a color with the background given in hex
+func BgHex(hex string) *Color {
+	rgb, err := ParseHex(hex)
+	if err != nil {
+		return New()
+	}
+	return New().AddBgRGB(rgb.R, rgb.G, rgb.B)
+}
+
+// HSL returns a color with the foreground given as hue in degrees and
+// saturation and lightness from 0 to 1
+func HSL(h, s, l float64) *Color {
+	rgb := hslToRGB(h, s, l)
+	return New().AddRGB(rgb.R, rgb.G, rgb.B)
+}
+
+// Ansi256 returns a color with foreground n of the xterm 256-color palette
+func Ansi256(n uint8) *Color {
+	c := &Color{fg: &extColor{index: int(n)}}
+	c.compile()
+	return c
+}
+
+// BgAnsi256 returns a color with background n of the 256-color palette
+func BgAnsi256(n uint8) *Color {
+	c := &Color{bg: &extColor{index: int(n)}}
+	c.compile()
+	return c
+}
+
+// AddRGB sets a 24-bit foreground, replacing any previous RGB or palette
+// foreground
+func (c *Color) AddRGB(r, g, b uint8) *Color {
+	c.fg = &extColor{rgb: RGBColor{r, g, b}, index: -1}
+	c.compile()
+	return c
+}
+
+// AddBgRGB sets a 24-bit background
+func (c *Color) AddBgRGB(r, g, b uint8) *Color {
+	c.bg = &extColor{rgb: RGBColor{r, g, b}, index: -1}
+	c.compile()
+	return c
+}
+
+// ParseHex parses "#rrggbb", "#rgb" or the same without "#"
+func ParseHex(hex string) (RGBColor, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return RGBColor{}, fmt.Errorf("color: invalid hex color %q", hex)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return RGBColor{}, fmt.Errorf("color: invalid hex color %q", hex)
+	}
+	return RGBColor{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// Hex returns the color as "#rrggbb"
+func (c RGBColor) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// sgr returns the SGR parameters selecting e as a foreground or background
+// for profile p
+func (e *extColor) sgr(p Profile, background bool) string {
+	base := 38
+	if background {
+		base = 48
+	}
+	switch {
+	case p == ProfileTrueColor && e.index < 0:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, e.rgb.R, e.rgb.G, e.rgb.B)
+	case p >= Profile256:
+		index := e.index
+		if index < 0 {
+			index = nearest256(e.rgb)
+		}
+		return fmt.Sprintf("%d;5;%d", base, index)
+	}
+
+	// 16 colors: 30-37 and 90-97, or 40-47 and 100-107
+	index := e.index
+	if index < 0 || index > 15 {
+		rgb := e.rgb
+		if index > 15 {
+			rgb = palette256(index)
+		}
+		index = nearest16(rgb)
+	}
+	code := base - 8 + index
+	if index > 7 {
+		code = base + 52 + index - 8
+	}
+	return strconv.Itoa(code)
+}
+
+// ansi16 holds the xterm default values of the 16 basic colors
+var ansi16 = [16]RGBColor{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 cube of the 256 palette
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// palette256 returns the RGB value of entry n of the xterm 256 palette
+func palette256(n int) RGBColor {
+	switch {
+	case n < 16:
+		return ansi16[n]
+	case n < 232:
+		n -= 16
+		return RGBColor{cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]}
+	}
+	v := uint8(8 + (n-232)*10)
+	return RGBColor{v, v, v}
+}
+
+var (
+	nearestMu    sync.Mutex
+	nearestCache = map[[2]int]int{} // {rgb, palette size} -> entry
+)
+
+// nearest256 returns the palette entry from 16 to 255 closest to c. The
+// first 16 entries are skipped since terminal themes change them.
+func nearest256(c RGBColor) int {
+	return nearest(c, 256, 16)
+}
+
+// nearest16 returns the basic color closest to c
+func nearest16(c RGBColor) int {
+	return nearest(c, 16, 0)
+}
+
+// nearest searches palette entries [from, size) for the one with the
+// smallest perceptual distance to c, measured in CIELAB
+func nearest(c RGBColor, size, from int) int {
+	key := [2]int{int(c.R)<<16 | int(c.G)<<8 | int(c.B), size}
+	nearestMu.Lock()
+	defer nearestMu.Unlock()
+	if n, ok := nearestCache[key]; ok {
+		return n
+	}
+
+	target := toLab(c)
+	best, bestDist := from, math.Inf(1)
+	for n := from; n < size; n++ {
+		if d := labDistance(target, toLab(palette256(n))); d < bestDist {
+			best, bestDist = n, d
+		}
+	}
+	if len(nearestCache) >= 4096 {
+		clear(nearestCache)
+	}
+	nearestCache[key] = best
+	return best
+}
+
+// lab is a color in CIELAB space
+type lab struct {
+	L, A, B float64
+}
+
+// toLab converts an sRGB color to CIELAB with a D65 white point
+func toLab(c RGBColor) lab {
+	r, g, b := linearize(c.R), linearize(c.G), linearize(c.B)
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// linearize converts an sRGB channel to linear light from 0 to 1
+func linearize(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// labDistance is the CIE76 color difference
+func labDistance(a, b lab) float64 {
+	dl, da, db := a.L-b.L, a.A-b.A, a.B-b.B
+	return math.Sqrt(dl*dl + da*da + db*db)
+}
+
+// hslToRGB converts hue in degrees and saturation and lightness from 0 to
+// 1 to RGB
+func hslToRGB(h, s, l float64) RGBColor {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = math.Max(0, math.Min(1, s))
+	l = math.Max(0, math.Min(1, l))
+
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = chroma, x
+	case h < 120:
+		r, g = x, chroma
+	case h < 180:
+		g, b = chroma, x
+	case h < 240:
+		g, b = x, chroma
+	case h < 300:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := l - chroma/2
+	to8 := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return RGBColor{to8(r), to8(g), to8(b)}
+}
@@ -0,0 +1,202 @@
+package color
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Theme holds the styles of the semantic roles used by ErrorText, the
+// semantic short methods and the semantic markup tags such as [error]
+type Theme struct {
+	Error   *Color
+	Warning *Color
+	Success *Color
+	Info    *Color
+	Muted   *Color // secondary text such as hints and defaults
+	Accent  *Color // highlighted values
+	Heading *Color
+	Code    *Color // commands, paths and identifiers
+}
+
+// themeRoles are the role names used by markup tags and theme files
+var themeRoles = []string{"error", "warning", "success", "info", "muted", "accent", "heading", "code"}
+
+// role returns the field of t for the role called name
+func (t *Theme) role(name string) **Color {
+	switch name {
+	case "error":
+		return &t.Error
+	case "warning":
+		return &t.Warning
+	case "success":
+		return &t.Success
+	case "info":
+		return &t.Info
+	case "muted":
+		return &t.Muted
+	case "accent":
+		return &t.Accent
+	case "heading":
+		return &t.Heading
+	case "code":
+		return &t.Code
+	}
+	return nil
+}
+
+// Style returns the style of the role called name, such as "error". Roles
+// the theme leaves nil have no style.
+func (t *Theme) Style(name string) (*Color, bool) {
+	field := t.role(strings.ToLower(name))
+	if field == nil {
+		return nil, false
+	}
+	if *field == nil {
+		return New(), true
+	}
+	return *field, true
+}
+
+// builtinThemes describes the built-in themes in markup tag syntax
+var builtinThemes = map[string]map[string]string{
+	// 16 colors that read well on dark backgrounds
+	"dark": {
+		"error": "bold red", "warning": "bold yellow", "success": "bold green", "info": "cyan",
+		"muted": "bright_black", "accent": "bold magenta", "heading": "bold underline", "code": "yellow",
+	},
+	// darker shades for light backgrounds, where yellow and cyan fade
+	"light": {
+		"error": "bold #b31d28", "warning": "bold #9a6700", "success": "bold #1a7f37", "info": "#0550ae",
+		"muted": "#6e7781", "accent": "bold #8250df", "heading": "bold underline", "code": "italic #953800",
+	},
+	// strong colors and backgrounds instead of dim shades
+	"high-contrast": {
+		"error": "bold bright_white on red", "warning": "bold black on bright_yellow",
+		"success": "bold black on bright_green", "info": "bold bright_cyan",
+		"muted": "white", "accent": "bold underline bright_magenta",
+		"heading": "bold underline bright_white", "code": "bold bright_yellow",
+	},
+	// blue for success, so no two roles differ only by red and green; basic
+	// colors keep the roles apart on 16-color terminals too
+	"colorblind": {
+		"error": "bold bright_red", "warning": "bold yellow", "success": "bold bright_blue", "info": "cyan",
+		"muted": "bright_black", "accent": "bold magenta", "heading": "bold underline", "code": "italic",
+	},
+}
+
+// ThemeNames lists the names of the built-in themes accepted by
+// BuiltinTheme
+func ThemeNames() []string {
+	return []string{"dark", "light", "high-contrast", "colorblind"}
+}
+
+// BuiltinTheme returns a new copy of the built-in theme called name: "dark"
+// (the default), "light", "high-contrast" or "colorblind"
+func BuiltinTheme(name string) (*Theme, bool) {
+	specs, ok := builtinThemes[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	t := &Theme{}
+	for role, spec := range specs {
+		style, err := parseStyle(spec)
+		if err != nil {
+			panic(err) // the built-in specs are fixed
+		}
+		*t.role(role) = style
+	}
+	return t, true
+}
+
+// ParseTheme reads a theme from a JSON object mapping roles to styles in
+// markup tag syntax. The optional "base" key names a built-in theme that
+// supplies the roles not listed; it defaults to "dark".
+//
+//	{"base": "light", "error": "bold white on #b31d28", "code": "italic"}
+func ParseTheme(data []byte) (*Theme, error) {
+	var specs map[string]string
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("color: reading theme: %w", err)
+	}
+	base := "dark"
+	if name, ok := specs["base"]; ok {
+		base = name
+		delete(specs, "base")
+	}
+	t, ok := BuiltinTheme(base)
+	if !ok {
+		return nil, fmt.Errorf("color: unknown theme %q", base)
+	}
+	for role, spec := range specs {
+		field := t.role(strings.ToLower(role))
+		if field == nil {
+			return nil, fmt.Errorf("color: unknown theme role %q (roles are %s)", role, strings.Join(themeRoles, ", "))
+		}
+		style, err := parseStyle(strings.Join(strings.Fields(strings.ToLower(spec)), " "))
+		if err != nil {
+			return nil, err
+		}
+		*field = style
+	}
+	return t, nil
+}
+
+// LoadTheme reads a theme from a JSON file; see ParseTheme
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("color: %w", err)
+	}
+	return ParseTheme(data)
+}
+
+var (
+	themeMu      sync.Mutex
+	currentTheme *Theme
+)
+
+// CurrentTheme returns the theme the semantic helpers use. The first time
+// it is needed it is taken from FMTPY_THEME, which holds the name of a
+// built-in theme or the path of a theme file; otherwise it is "dark".
+func CurrentTheme() *Theme {
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	if currentTheme == nil {
+		currentTheme = themeFromEnv(os.Getenv("FMTPY_THEME"))
+	}
+	return currentTheme
+}
+
+// SetTheme makes t the theme of the semantic helpers and returns the
+// previous one. With nil the theme is taken from FMTPY_THEME again.
+func SetTheme(t *Theme) *Theme {
+	prev := CurrentTheme()
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	currentTheme = t
+	return prev
+}
+
+// themeFromEnv returns the theme selected by value, falling back to the
+// dark theme when it names neither a built-in theme nor a readable file
+func themeFromEnv(value string) *Theme {
+	if value != "" {
+		if t, ok := BuiltinTheme(value); ok {
+			return t
+		}
+		if t, err := LoadTheme(value); err == nil {
+			return t
+		}
+	}
+	t, _ := BuiltinTheme("dark")
+	return t
+}
+
+// themed returns the style of a role in the current theme
+func themed(role string) *Color {
+	style, _ := CurrentTheme().Style(role)
+	return style
+}
@@ -0,0 +1,230 @@
+package color
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// escapeLen returns the length of the escape sequence at the start of s, or
+// 0 if s does not start with one. CSI sequences such as colors end at their
+// final byte; OSC sequences such as links and the other string sequences end
+// at BEL or ESC \. A malformed CSI sequence ends before the first byte that
+// does not belong to it, and an unterminated one runs to the end of s.
+func escapeLen(s string) int {
+	if len(s) == 0 || s[0] != '\x1b' {
+		return 0
+	}
+	if len(s) == 1 {
+		return 1
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			switch c := s[i]; {
+			case c >= 0x40 && c <= 0x7e:
+				return i + 1
+			case c < 0x20 || c > 0x3f:
+				return i
+			}
+		}
+		return len(s)
+	case ']', 'P', 'X', '^', '_':
+		for i := 2; i < len(s); i++ {
+			switch {
+			case s[i] == '\a':
+				return i + 1
+			case s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\':
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	return 2
+}
+
+// Strip removes ANSI escape sequences such as colors and links from s
+func Strip(s string) string {
+	i := strings.IndexByte(s, '\x1b')
+	if i < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i >= 0 {
+		b.WriteString(s[:i])
+		s = s[i+escapeLen(s[i:]):]
+		i = strings.IndexByte(s, '\x1b')
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// VisibleWidth returns the number of terminal columns s takes up. Escape
+// sequences take none, East Asian wide characters and most emoji take two,
+// and combining marks and control characters take none.
+func VisibleWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// Truncate shortens s to at most width columns, ending it with tail such
+// as "…". Escape sequences before the cut are kept, and styles and links
+// still open there are closed after the tail.
+//
+//	color.Truncate(color.Red("a long message"), 8, "…") // red "a long …"
+func Truncate(s string, width int, tail string) string {
+	if VisibleWidth(s) <= width {
+		return s
+	}
+	limit := width - VisibleWidth(tail)
+	if limit < 0 {
+		tail, limit = Truncate(tail, width, ""), 0
+	}
+
+	var b strings.Builder
+	styled, linked := false, false
+	used := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			seq := s[i : i+n]
+			switch {
+			case isSGR(seq):
+				params := seq[2 : len(seq)-1]
+				styled = params != "" && params != "0"
+			case strings.HasPrefix(seq, "\x1b]8;"):
+				linked = linkTarget(seq) != ""
+			}
+			b.WriteString(seq)
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := runeWidth(r)
+		if used+w > limit {
+			break
+		}
+		b.WriteString(s[i : i+size])
+		used += w
+		i += size
+	}
+	b.WriteString(tail)
+	if linked {
+		b.WriteString("\x1b]8;;\x1b\\")
+	}
+	if styled {
+		b.WriteString(resetSeq)
+	}
+	return b.String()
+}
+
+// isSGR reports whether seq is a complete SGR (style) sequence
+func isSGR(seq string) bool {
+	return len(seq) >= 3 && seq[1] == '[' && seq[len(seq)-1] == 'm'
+}
+
+// linkTarget returns the URI of an OSC 8 link sequence, which is empty for
+// the sequence that ends a link
+func linkTarget(seq string) string {
+	body := strings.TrimPrefix(seq, "\x1b]8;")
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "\a"), "\x1b\\")
+	if i := strings.IndexByte(body, ';'); i >= 0 {
+		return body[i+1:]
+	}
+	return ""
+}
+
+// Center centers s in width columns with spaces, measuring s by its
+// visible width
+func Center(s string, width int) string {
+	n := width - VisibleWidth(s)
+	if n <= 0 {
+		return s
+	}
+	return strings.Repeat(" ", n/2) + s + strings.Repeat(" ", n-n/2)
+}
+
+// LeftPad pads s on the left with padChar to width columns, measuring s by
+// its visible width
+func LeftPad(s string, width int, padChar string) string {
+	n := width - VisibleWidth(s)
+	if n <= 0 {
+		return s
+	}
+	return padding(n, padChar) + s
+}
+
+// RightPad pads s on the right with padChar to width columns
+func RightPad(s string, width int, padChar string) string {
+	n := width - VisibleWidth(s)
+	if n <= 0 {
+		return s
+	}
+	return s + padding(n, padChar)
+}
+
+// padding returns n columns of padChar, filling with spaces where a wide
+// padChar does not fit
+func padding(n int, padChar string) string {
+	w := VisibleWidth(padChar)
+	if w == 0 {
+		padChar, w = " ", 1
+	}
+	return strings.Repeat(padChar, n/w) + strings.Repeat(" ", n%w)
+}
+
+// runeWidth returns the number of columns r takes up
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || r >= 0x7f && r < 0xa0:
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1160 && r <= 0x11ff: // Hangul medial vowels and final consonants
+		return 0
+	case r < 0x1100:
+		return 1
+	}
+	i := sort.Search(len(wideRanges), func(i int) bool { return wideRanges[i][1] >= r })
+	if i < len(wideRanges) && wideRanges[i][0] <= r {
+		return 2
+	}
+	return 1
+}
+
+// wideRanges are the East Asian wide and fullwidth characters and the emoji
+// shown as two columns, sorted by start
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18cff}, {0x1b000, 0x1b2ff}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f251}, {0x1f300, 0x1f320},
+	{0x1f32d, 0x1f335}, {0x1f337, 0x1f37c}, {0x1f37e, 0x1f393}, {0x1f3a0, 0x1f3ca},
+	{0x1f3cf, 0x1f3d3}, {0x1f3e0, 0x1f3f0}, {0x1f3f4, 0x1f3f4}, {0x1f3f8, 0x1f43e},
+	{0x1f440, 0x1f440}, {0x1f442, 0x1f4fc}, {0x1f4ff, 0x1f53d}, {0x1f54b, 0x1f54e},
+	{0x1f550, 0x1f567}, {0x1f57a, 0x1f57a}, {0x1f595, 0x1f596}, {0x1f5a4, 0x1f5a4},
+	{0x1f5fb, 0x1f64f}, {0x1f680, 0x1f6c5}, {0x1f6cc, 0x1f6cc}, {0x1f6d0, 0x1f6d2},
+	{0x1f6d5, 0x1f6d7}, {0x1f6dc, 0x1f6df}, {0x1f6eb, 0x1f6ec}, {0x1f6f4, 0x1f6fc},
+	{0x1f7e0, 0x1f7eb}, {0x1f7f0, 0x1f7f0}, {0x1f90c, 0x1f93a}, {0x1f93c, 0x1f945},
+	{0x1f947, 0x1f9ff}, {0x1fa70, 0x1faff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
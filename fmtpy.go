@@ -2,44 +2,15 @@
 package fmtpy
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
-)
-
-var reader = bufio.NewReader(os.Stdin)
-
-// InputValue is a special type that can convert to different types
-type InputValue string
-
-// String returns the string value
-func (iv InputValue) String() string {
-	return string(iv)
-}
 
-// Int converts to integer, returns 0 if conversion fails
-func (iv InputValue) Int() int {
-	if i, err := strconv.Atoi(strings.TrimSpace(string(iv))); err == nil {
-		return i
-	}
-	return 0
-}
-
-// Float converts to float64, returns 0.0 if conversion fails
-func (iv InputValue) Float() float64 {
-	if f, err := strconv.ParseFloat(strings.TrimSpace(string(iv)), 64); err == nil {
-		return f
-	}
-	return 0.0
-}
+	"github.com/grandpaej/fmtpy/v2/input"
+)
 
-// Bool converts to boolean (y/yes/true/1 = true, everything else = false)
-func (iv InputValue) Bool() bool {
-	s := strings.ToLower(strings.TrimSpace(string(iv)))
-	return s == "y" || s == "yes" || s == "true" || s == "1"
-}
+// InputValue is a special type that can convert to different types.
+// It is the same type as input.InputValue.
+type InputValue = input.InputValue
 
 // Input prompts the user for input and returns an InputValue that can be converted to any type
 // Usage examples:
@@ -48,10 +19,30 @@ func (iv InputValue) Bool() bool {
 //	var age int = Input("Enter age: ").Int()
 //	score := Input("Enter score: ").Float()
 //	confirmed := Input("Confirm (y/n): ").Bool()
+//
+// Input shares the default console of the input package, so answers can be
+// scripted with FMTPY_ANSWERS and sessions recorded with FMTPY_RECORD.
+// Ctrl-C ends the line and exits the program like Exit, unless an
+// OnInterrupt hook decides otherwise.
 func Input(prompt string) InputValue {
-	fmt.Print(prompt)
-	text, _ := reader.ReadString('\n')
-	return InputValue(strings.TrimSpace(text))
+	answer, err := input.DefaultConsole().ReadLine(prompt)
+	if err == input.ErrInterrupted {
+		Exit(input.InterruptExitCode)
+	}
+	return InputValue(answer)
+}
+
+// OnInterrupt registers fn to run when Ctrl-C is pressed while a prompt is
+// waiting. Return true to keep waiting for the answer, or false to let the
+// prompt end: Input exits the program and prompts with an error result
+// return input.ErrInterrupted.
+//
+//	fmtpy.OnInterrupt(func() bool {
+//	    quit, _ := input.Confirm("Are you sure you want to quit?", input.DefaultNo())
+//	    return !quit
+//	})
+func OnInterrupt(fn func() bool) {
+	input.OnInterrupt(fn)
 }
 
 // Print formats and prints the given values.
@@ -65,6 +56,7 @@ func Print(format interface{}, args ...interface{}) {
 		if strings.HasPrefix(v, "f\"") || strings.HasPrefix(v, "f'") {
 			// Python-style formatting
 			v = v[2 : len(v)-1] // Remove f" and "
+			args = applySpecs(v, args)
 			v = convertPythonStyle(v)
 			fmt.Printf(v, args...)
 		} else if len(args) > 0 {
@@ -94,6 +86,8 @@ func Print(format interface{}, args ...interface{}) {
 
 // convertPythonStyle converts Python-style format strings to Go format strings
 // Example: "Hello {name}" -> "Hello %v"
+// Placeholders with the locale spec {total:n} also become %v; their
+// arguments are formatted beforehand by applySpecs.
 func convertPythonStyle(s string) string {
 	var result strings.Builder
 	inBrace := false
@@ -1,8 +1,23 @@
 package fmtpy
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/grandpaej/fmtpy/v2/color"
+	"github.com/grandpaej/fmtpy/v2/fmtpytest"
 	"github.com/grandpaej/fmtpy/v2/input"
 )
 
@@ -243,3 +258,1496 @@ func TestInputValue(t *testing.T) {
 		t.Errorf("InputValue.Bool() should return false for 'no'")
 	}
 }
+
+func TestFuzzyMatch(t *testing.T) {
+	if _, _, ok := input.FuzzyMatch("xyz", "feature/login"); ok {
+		t.Errorf("FuzzyMatch should not match missing characters")
+	}
+
+	_, positions, ok := input.FuzzyMatch("flog", "feature/login")
+	if !ok {
+		t.Fatalf("FuzzyMatch failed to match a subsequence")
+	}
+	if len(positions) != 4 || positions[0] != 0 || positions[1] != 8 {
+		t.Errorf("FuzzyMatch positions = %v; want [0 8 9 10]", positions)
+	}
+
+	// Dense, word-boundary matches outrank scattered ones
+	dense, _, _ := input.FuzzyMatch("main", "origin/main")
+	scattered, _, _ := input.FuzzyMatch("main", "my-admin-panel")
+	if dense <= scattered {
+		t.Errorf("FuzzyMatch scored %q (%d) below %q (%d)", "origin/main", dense, "my-admin-panel", scattered)
+	}
+
+	// Smart case: an uppercase pattern is matched exactly
+	if _, _, ok := input.FuzzyMatch("Main", "main"); ok {
+		t.Errorf("FuzzyMatch with uppercase pattern should be case-sensitive")
+	}
+	if _, _, ok := input.FuzzyMatch("main", "MAIN"); !ok {
+		t.Errorf("FuzzyMatch with lowercase pattern should ignore case")
+	}
+}
+
+func TestInputValueScan(t *testing.T) {
+	var a, b int
+	var name string
+	var score float64
+	if err := InputValue("3 -4 bob 9.5").Scan(&a, &b, &name, &score); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if a != 3 || b != -4 || name != "bob" || score != 9.5 {
+		t.Errorf("Scan got %d %d %q %v", a, b, name, score)
+	}
+
+	if err := InputValue("1 2 3").Scan(&a, &b); err == nil {
+		t.Errorf("Scan should fail when there are too many values")
+	}
+	if err := InputValue("1 x").Scan(&a, &b); err == nil {
+		t.Errorf("Scan should fail for a non-integer")
+	}
+
+	nums, err := InputValue(" 1 2  3 ").Ints()
+	if err != nil || len(nums) != 3 || nums[2] != 3 {
+		t.Errorf("Ints() = %v, %v", nums, err)
+	}
+	if _, err := InputValue("1 two").Ints(); err == nil {
+		t.Errorf("Ints() should fail for a non-integer")
+	}
+
+	floats, err := InputValue("1.5 2").Floats()
+	if err != nil || len(floats) != 2 || floats[0] != 1.5 {
+		t.Errorf("Floats() = %v, %v", floats, err)
+	}
+
+	parts := InputValue("a, b ,c").SplitBy(",")
+	if len(parts) != 3 || parts[1] != "b" {
+		t.Errorf("SplitBy() = %q", parts)
+	}
+	if len(InputValue("").SplitBy(",")) != 0 {
+		t.Errorf("SplitBy() on empty value should return no parts")
+	}
+
+	kv, err := InputValue(`a=1 b="two words" c='x y' d=say\ hi`).KeyValues()
+	if err != nil {
+		t.Fatalf("KeyValues failed: %v", err)
+	}
+	if kv["a"] != "1" || kv["b"] != "two words" || kv["c"] != "x y" || kv["d"] != "say hi" {
+		t.Errorf("KeyValues() = %q", kv)
+	}
+	if _, err := InputValue(`a="open`).KeyValues(); err == nil {
+		t.Errorf("KeyValues should fail on an unterminated quote")
+	}
+}
+
+func TestScanner(t *testing.T) {
+	sc := NewScanner(strings.NewReader("3 -7\n9223372036854775807 2.5 word\r\nrest of line\nlast"))
+	if n := sc.NextInt(); n != 3 {
+		t.Errorf("NextInt() = %d; want 3", n)
+	}
+	if n := sc.NextInt(); n != -7 {
+		t.Errorf("NextInt() = %d; want -7", n)
+	}
+	if n := sc.NextInt64(); n != math.MaxInt64 {
+		t.Errorf("NextInt64() = %d; want MaxInt64", n)
+	}
+	if f := sc.NextFloat(); f != 2.5 {
+		t.Errorf("NextFloat() = %v; want 2.5", f)
+	}
+	if s := sc.NextString(); s != "word" {
+		t.Errorf("NextString() = %q; want %q", s, "word")
+	}
+	if line := sc.NextLine(); line != "" {
+		t.Errorf("NextLine() after a token = %q; want the empty rest of the line", line)
+	}
+	if line := sc.NextLine(); line != "rest of line" {
+		t.Errorf("NextLine() = %q", line)
+	}
+	if line := sc.NextLine(); line != "last" {
+		t.Errorf("NextLine() = %q", line)
+	}
+	if sc.HasNext() || sc.Err() != nil {
+		t.Errorf("Scanner should be exhausted without error, got %v", sc.Err())
+	}
+	sc.NextInt()
+	if sc.Err() != io.EOF {
+		t.Errorf("Err() after reading past the end = %v; want io.EOF", sc.Err())
+	}
+
+	// Tokens spanning buffer refills and invalid numbers
+	long := strings.Repeat("x", 100000)
+	sc = NewScanner(strings.NewReader(long + " 12a"))
+	if s := sc.NextString(); s != long {
+		t.Errorf("NextString() lost data across refills (len %d)", len(s))
+	}
+	if sc.NextInt(); sc.Err() == nil {
+		t.Errorf("NextInt() should fail for %q", "12a")
+	}
+
+	// A token that overflows is still checked to the end
+	for _, tt := range []struct{ in, want string }{
+		{"99999999999999999999x", "not an integer"},
+		{"99999999999999999999", "out of range"},
+		{"-9223372036854775809", "out of range"},
+		{strings.Repeat("9", 100000), `"99999999999999999999999999999999..." is out of range`},
+		{strings.Repeat("9", 100000) + "x", `"99999999999999999999999999999999..." is not an integer`},
+	} {
+		sc = NewScanner(strings.NewReader(tt.in))
+		if sc.NextInt64(); sc.Err() == nil || !strings.Contains(sc.Err().Error(), tt.want) {
+			t.Errorf("NextInt64(%q) error = %v; want %q", tt.in, sc.Err(), tt.want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteInt(-42)
+	w.Println(" ok")
+	w.Printf("%d-%s", 1, "x")
+	if buf.Len() != 0 {
+		t.Errorf("Writer should buffer until Flush")
+	}
+	w.Flush()
+	if buf.String() != "-42 ok\n1-x" {
+		t.Errorf("Writer output = %q", buf.String())
+	}
+
+	// Close flushes and forgets the Writer
+	registered := func(w *Writer) bool {
+		writersMu.Lock()
+		defer writersMu.Unlock()
+		for _, other := range writers {
+			if other == w {
+				return true
+			}
+		}
+		return false
+	}
+	if !registered(w) {
+		t.Errorf("NewWriter did not register the Writer for Exit")
+	}
+	w.Print("!")
+	if err := w.Close(); err != nil || buf.String() != "-42 ok\n1-x!" {
+		t.Errorf("Close() = %v, output %q", err, buf.String())
+	}
+	if registered(w) {
+		t.Errorf("Close did not unregister the Writer")
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+}
+
+// benchInput is 200k integers, one per line
+var benchInput = func() string {
+	var b strings.Builder
+	for i := 0; i < 200000; i++ {
+		b.WriteString(strconv.Itoa(i*7919 - 500000))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}()
+
+func BenchmarkScannerNextInt(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sc := NewScanner(strings.NewReader(benchInput))
+		for sc.HasNext() {
+			sc.NextInt()
+		}
+	}
+}
+
+func BenchmarkBufioScannerAtoi(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sc := bufio.NewScanner(strings.NewReader(benchInput))
+		sc.Split(bufio.ScanWords)
+		for sc.Scan() {
+			strconv.Atoi(sc.Text())
+		}
+	}
+}
+
+func BenchmarkFmtFscan(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := bufio.NewReader(strings.NewReader(benchInput))
+		var n int
+		for {
+			if _, err := fmt.Fscan(r, &n); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func TestPythonNumberParsing(t *testing.T) {
+	ints := []struct {
+		s    string
+		base int
+		want int
+		ok   bool
+	}{
+		{"1_000", 10, 1000, true},
+		{" +5 ", 10, 5, true},
+		{"0x1F", 0, 31, true},
+		{"0b101", 0, 5, true},
+		{"0o17", 0, 15, true},
+		{"0x_ff", 16, 255, true},
+		{"ff", 16, 255, true},
+		{"z", 36, 35, true},
+		{"010", 0, 0, false},
+		{"000", 0, 0, true},
+		{"1__0", 10, 0, false},
+		{"_1", 10, 0, false},
+		{"1_", 10, 0, false},
+		{"--5", 10, 0, false},
+		{"3.0", 10, 0, false},
+		{"0x1F", 10, 0, false},
+		{"12", 1, 0, false},
+	}
+	for _, tt := range ints {
+		got, err := input.ParseInt(tt.s, tt.base)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseInt(%q, %d) = %d, %v; want %d, ok=%v", tt.s, tt.base, got, err, tt.want, tt.ok)
+		}
+	}
+
+	floats := []struct {
+		s    string
+		want float64
+		ok   bool
+	}{
+		{" 3.0 ", 3, true},
+		{"1_000.5", 1000.5, true},
+		{"-inf", math.Inf(-1), true},
+		{"Infinity", math.Inf(1), true},
+		{"1e400", math.Inf(1), true},
+		{".5", 0.5, true},
+		{"0x1p-2", 0, false},
+		{"1__0", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range floats {
+		got, err := input.ParseFloat(tt.s)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseFloat(%q) = %v, %v; want %v, ok=%v", tt.s, got, err, tt.want, tt.ok)
+		}
+	}
+	if f, err := input.ParseFloat("nan"); err != nil || !math.IsNaN(f) {
+		t.Errorf("ParseFloat(%q) = %v, %v; want NaN", "nan", f, err)
+	}
+
+	// Lenient conversions accept leading zeros, prefixes and whole decimals
+	if n, err := input.ToIntE("007"); err != nil || n != 7 {
+		t.Errorf("ToIntE(%q) = %d, %v", "007", n, err)
+	}
+	if input.ToInt("0x10") != 16 || input.ToInt(" 3.0 ") != 3 {
+		t.Errorf("ToInt should accept Python literals and whole decimals")
+	}
+	if _, err := input.ToIntE("3.5"); err == nil {
+		t.Errorf("ToIntE(%q) should fail", "3.5")
+	}
+	if _, err := input.ToBoolE("maybe"); err == nil {
+		t.Errorf("ToBoolE(%q) should fail", "maybe")
+	}
+	if b, err := input.ToBoolE(" No "); err != nil || b {
+		t.Errorf("ToBoolE(%q) = %v, %v", " No ", b, err)
+	}
+}
+
+func TestInputValueConversions(t *testing.T) {
+	if InputValue("9_000_000_000").Int64() != 9000000000 {
+		t.Errorf("Int64() failed")
+	}
+	if InputValue("-1").Uint() != 0 || InputValue("0xff").Uint() != 255 {
+		t.Errorf("Uint() failed")
+	}
+	if InputValue("123456789012345678901234567890").BigInt().String() != "123456789012345678901234567890" {
+		t.Errorf("BigInt() failed")
+	}
+	if InputValue("1+2j").Complex() != complex(1, 2) || InputValue("(3-4j)").Complex() != complex(3, -4) {
+		t.Errorf("Complex() failed")
+	}
+	if InputValue("1h30m").Duration() != 90*time.Minute || InputValue("2.5").Duration() != 2500*time.Millisecond {
+		t.Errorf("Duration() failed")
+	}
+
+	var small int8
+	if err := InputValue("300").Scan(&small); err == nil {
+		t.Errorf("Scan into int8 should fail for 300")
+	}
+}
+
+func TestLocaleNumbers(t *testing.T) {
+	formats := []struct {
+		v      float64
+		locale string
+		want   string
+	}{
+		{1234567.5, "en_US", "1,234,567.5"},
+		{1234567.5, "de_DE", "1.234.567,5"},
+		{-1234567, "en_IN", "-12,34,567"},
+		{1234.25, "fr_FR.UTF-8", "1 234,25"},
+		{999, "de", "999"},
+		{1234.5, "C", "1234.5"},
+	}
+	for _, tt := range formats {
+		if got := input.FormatNumber(tt.v, tt.locale); got != tt.want {
+			t.Errorf("FormatNumber(%v, %q) = %q; want %q", tt.v, tt.locale, got, tt.want)
+		}
+	}
+
+	de, _ := input.LookupLocale("de_DE")
+	if got := de.FormatCurrency(-1234.5); got != "-1.234,50 €" {
+		t.Errorf("FormatCurrency = %q", got)
+	}
+	if got := de.FormatPercent(0.255, 1); got != "25,5 %" {
+		t.Errorf("FormatPercent = %q", got)
+	}
+	us, _ := input.LookupLocale("en_US")
+	if got := us.FormatCurrency(1234.5); got != "$1,234.50" {
+		t.Errorf("FormatCurrency = %q", got)
+	}
+
+	parses := []struct {
+		s      string
+		locale string
+		want   float64
+		ok     bool
+	}{
+		{"1.234,56", "de_DE", 1234.56, true},
+		{"1.234", "de_DE", 1234, true},
+		{"3,5", "de_DE", 3.5, true},
+		{"3.5", "de_DE", 3.5, true}, // not valid German grouping, read as Python float
+		{"12,34,567.5", "en_IN", 1234567.5, true},
+		{"1 234,5", "fr_FR", 1234.5, true},
+		{"1,234.5", "en_US", 1234.5, true},
+		{"12,34", "en_US", 0, false},
+		{"€ 12,50", "de_DE", 12.5, true},
+		{"12,50\u00a0€", "de_DE", 12.5, true},
+		{"1\u00a0234\u00a0567,5", "ru_RU", 1234567.5, true},
+		{"1 234,5", "ru_RU", 1234.5, true},
+		{"25,5\u00a0%", "ru_RU", 25.5, true},
+		{"1\u00a02\u00a03", "ru_RU", 0, false}, // groups of three only
+		{"12\u00a034", "ru_RU", 0, false},
+		{"1\u00a0234", "de_DE", 0, false}, // de_DE groups with dots
+		{"1\u00a0234", "en_US", 0, false},
+	}
+	for _, tt := range parses {
+		got, err := input.ParseNumber(tt.s, tt.locale)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseNumber(%q, %q) = %v, %v; want %v, ok=%v", tt.s, tt.locale, got, err, tt.want, tt.ok)
+		}
+	}
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_NUMERIC", "de_DE.UTF-8")
+	if InputValue("1.234,56").Float() != 1234.56 {
+		t.Errorf("InputValue.Float() should use the locale from LC_NUMERIC")
+	}
+	if err := input.SetLocale("en_IN"); err != nil {
+		t.Fatal(err)
+	}
+	defer input.SetLocale("")
+	if input.CurrentLocale().Name != "en_IN" {
+		t.Errorf("SetLocale should override the environment")
+	}
+	if err := input.SetLocale("xx_YY"); err == nil {
+		t.Errorf("SetLocale should reject unknown locales")
+	}
+
+	// Under de_DE a dot groups thousands
+	input.SetLocale("de_DE")
+	if got := InputValue("1.234").Float(); got != 1234 {
+		t.Errorf(`de_DE Float("1.234") = %v; want 1234`, got)
+	}
+	if got := InputValue("1.5").Float(); got != 1.5 {
+		t.Errorf(`de_DE Float("1.5") = %v; want 1.5`, got)
+	}
+
+	// SetLocale may race with readers
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			input.SetLocale([]string{"de_DE", "en_US", ""}[i%3])
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		input.FormatNumber(1234.5, "")
+	}
+	<-done
+}
+
+func TestFormatSpec(t *testing.T) {
+	input.SetLocale("de_DE")
+	defer input.SetLocale("")
+
+	tests := []struct {
+		v    interface{}
+		spec string
+		want string
+	}{
+		{1234.5, "n", "1.234,5"},
+		{1234567.5, "n", "1,23457e+06"}, // six significant digits, like Python
+		{1234567.5, ".8n", "1.234.567,5"},
+		{-1234.5, ".2n", "-1,2e+03"},
+		{0.00001234, "n", "1,234e-05"},
+		{2.0, "n", "2"},
+		{1234567, "n", "1.234.567"},
+		{uint8(200), "n", "200"},
+		{uint64(1 << 63), "n", "9.223.372.036.854.775.808"},
+		{uint64(math.MaxUint64), "n", "18.446.744.073.709.551.615"},
+		{int64(math.MinInt64), "n", "-9.223.372.036.854.775.808"},
+		{int64(math.MaxInt64), "n", "9.223.372.036.854.775.807"},
+	}
+	for _, tt := range tests {
+		if got, ok := formatSpec(tt.v, tt.spec); !ok || got != tt.want {
+			t.Errorf("formatSpec(%v, %q) = %q, %v; want %q", tt.v, tt.spec, got, ok, tt.want)
+		}
+	}
+	for _, tt := range []struct {
+		v    interface{}
+		spec string
+	}{
+		{3.14159, ".2f"}, // only the locale type is handled
+		{"text", "n"},
+		{12, "xn"},
+		{12, ".2n"}, // precision is not allowed for integers
+	} {
+		if got, ok := formatSpec(tt.v, tt.spec); ok {
+			t.Errorf("formatSpec(%v, %q) = %q; want it left alone", tt.v, tt.spec, got)
+		}
+	}
+
+	args := applySpecs("{a} {b:n} {c:.2f}", []interface{}{1000, 1000, 1.5})
+	if fmt.Sprint(args) != "[1000 1.000 1.5]" {
+		t.Errorf("applySpecs = %v", args)
+	}
+}
+
+func TestScriptedAnswers(t *testing.T) {
+	var out, record bytes.Buffer
+	c := input.NewConsole(strings.NewReader("typed\n"), &out)
+	c.SetAnswers("Ada", "36")
+	c.SetAnswerMap(map[string]string{"Continue? (y/n):": "y"})
+	c.Record(&record)
+	prev := input.SetDefaultConsole(c)
+	defer input.SetDefaultConsole(prev)
+
+	if name := Input("Name: ").String(); name != "Ada" {
+		t.Errorf("scripted Input = %q; want %q", name, "Ada")
+	}
+	if !input.Ask("Continue?") {
+		t.Errorf("Ask should use the answer keyed by its prompt")
+	}
+	if age := input.InputInt("Age: "); age != 36 {
+		t.Errorf("scripted InputInt = %d; want 36", age)
+	}
+	if s := input.Input("Fallback: "); s != "typed" {
+		t.Errorf("Input after the script ran out = %q; want the typed answer", s)
+	}
+
+	if !strings.Contains(out.String(), "Name: Ada\n") {
+		t.Errorf("scripted answers should be echoed, got %q", out.String())
+	}
+	want := "# Name:\nAda\n# Continue? (y/n):\ny\n# Age:\n36\n# Fallback:\ntyped\n"
+	if record.String() != want {
+		t.Errorf("recording = %q; want %q", record.String(), want)
+	}
+
+	// A recording replays as an answers file
+	path := t.TempDir() + "/session.txt"
+	if err := os.WriteFile(path, record.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	replay := input.NewConsole(strings.NewReader(""), io.Discard)
+	if err := replay.LoadAnswers(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Ada", "y", "36", "typed"} {
+		if got := replay.Input("? "); got != want {
+			t.Errorf("replayed answer = %q; want %q", got, want)
+		}
+	}
+}
+
+func TestSelectFallback(t *testing.T) {
+	options := []string{"red", "green", "blue"}
+	use := func(in string) *bytes.Buffer {
+		var out bytes.Buffer
+		prev := input.SetDefaultConsole(input.NewConsole(strings.NewReader(in), &out))
+		t.Cleanup(func() { input.SetDefaultConsole(prev) })
+		return &out
+	}
+
+	use("2\n")
+	if i, err := input.Select("Pick:", options); i != 1 || err != nil {
+		t.Errorf("Select(2) = %d, %v; want 1, nil", i, err)
+	}
+
+	// Out-of-range and non-numeric answers ask again
+	out := use("0\n4\nred\n3\n")
+	if i, err := input.Select("Pick:", options); i != 2 || err != nil {
+		t.Errorf("Select after retries = %d, %v; want 2, nil", i, err)
+	}
+	if n := strings.Count(out.String(), "Please enter a number between 1 and 3"); n != 3 {
+		t.Errorf("Select printed %d retry messages; want 3:\n%s", n, out.String())
+	}
+	if !strings.Contains(out.String(), "  1) red\n  2) green\n  3) blue\n") {
+		t.Errorf("Select did not list the options:\n%s", out.String())
+	}
+
+	use("")
+	if _, err := input.Select("Pick:", options); err != io.EOF {
+		t.Errorf("Select at EOF: err = %v; want io.EOF", err)
+	}
+	if _, err := input.Select("Pick:", nil); err != input.ErrNoOptions {
+		t.Errorf("Select(nil) err = %v; want ErrNoOptions", err)
+	}
+	if _, err := input.MultiSelect("Pick:", nil); err != input.ErrNoOptions {
+		t.Errorf("MultiSelect(nil) err = %v; want ErrNoOptions", err)
+	}
+
+	many := []string{"a", "b", "c", "d", "e", "f", "g"}
+	for _, tt := range []struct {
+		in   string
+		want []int
+	}{
+		{"1,3 5-7\n", []int{0, 2, 4, 5, 6}},
+		{"2, 2 1-3,3\n", []int{0, 1, 2}}, // duplicates count once
+		{"7 1\n", []int{0, 6}},           // sorted
+		{"\n", []int{}},                  // empty selects nothing
+		{"3-1\n2\n", []int{1}},           // reversed range asks again
+		{"-2\n4\n", []int{3}},            // negative number asks again
+		{"1-9\n5\n", []int{4}},           // past the end asks again
+		{"a-b\n1\n", []int{0}},
+	} {
+		out := use(tt.in)
+		got, err := input.MultiSelect("Pick:", many)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) || got == nil {
+			t.Errorf("MultiSelect(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+		if retry := strings.Count(tt.in, "\n") > 1; retry != strings.Contains(out.String(), "is not a number or range between 1 and 7") {
+			t.Errorf("MultiSelect(%q) retry message shown = %v; want %v", tt.in, !retry, retry)
+		}
+	}
+}
+
+func TestFuzzyFindFallback(t *testing.T) {
+	files := []string{"main.go", "model.go", "readme.md", "Makefile"}
+	use := func(answers ...string) *bytes.Buffer {
+		var out bytes.Buffer
+		c := input.NewConsole(strings.NewReader(""), &out)
+		c.SetAnswers(answers...)
+		prev := input.SetDefaultConsole(c)
+		t.Cleanup(func() { input.SetDefaultConsole(prev) })
+		return &out
+	}
+
+	// No matches and an empty pick search again
+	out := use("zzz", "mod", "", "read", "1")
+	got, err := input.FuzzyFind("Files:", files)
+	if err != nil || fmt.Sprint(got) != "[2]" {
+		t.Errorf("FuzzyFind = %v, %v; want [2]", got, err)
+	}
+	for _, want := range []string{"Files: search: zzz\nNo matches, try again\n", "  1) model.go\n", "  1) readme.md\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("FuzzyFind output lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	use("go", "2,1")
+	if got, err := input.FuzzyFind("Files:", files, input.FuzzyMulti()); err != nil || fmt.Sprint(got) != "[0 1]" {
+		t.Errorf("FuzzyFind with FuzzyMulti = %v, %v; want [0 1]", got, err)
+	}
+	use("go", "1,2")
+	if got, err := input.FuzzyFind("Files:", files); err != io.EOF {
+		t.Errorf("FuzzyFind with two picks then EOF = %v, %v; want io.EOF", got, err)
+	}
+}
+
+func TestConfirmAndChoice(t *testing.T) {
+	use := func(in string) *bytes.Buffer {
+		var out bytes.Buffer
+		prev := input.SetDefaultConsole(input.NewConsole(strings.NewReader(in), &out))
+		t.Cleanup(func() { input.SetDefaultConsole(prev) })
+		return &out
+	}
+
+	for _, tt := range []struct {
+		in   string
+		opts []input.ConfirmOption
+		want bool
+		err  error
+		hint string
+	}{
+		{"y\n", nil, true, nil, "Go? [y/n] "},
+		{"NO\n", nil, false, nil, "Go? [y/n] "},
+		{"\n", []input.ConfirmOption{input.DefaultYes()}, true, nil, "Go? [Y/n] "},
+		{"\n", []input.ConfirmOption{input.DefaultNo()}, false, nil, "Go? [y/N] "},
+		{"", []input.ConfirmOption{input.DefaultYes()}, true, nil, "[Y/n]"}, // EOF gives the default
+		{"", nil, false, io.EOF, "[y/n]"},
+		{"oui\n", []input.ConfirmOption{input.ConfirmLanguage("fr")}, true, nil, "Go? [o/n] "},
+		{"nein\n", []input.ConfirmOption{input.ConfirmLanguage("DE")}, false, nil, "[j/n]"},
+		{"yes\n", []input.ConfirmOption{input.ConfirmLanguage("fr")}, true, nil, "[o/n]"}, // English stays accepted
+		{"\n", []input.ConfirmOption{input.ConfirmLanguage("ja"), input.DefaultYes()}, true, nil, "[はい/いいえ] (はい)"},
+		{"いいえ\n", []input.ConfirmOption{input.ConfirmLanguage("ja")}, false, nil, "[はい/いいえ]"},
+		{"da\n", []input.ConfirmOption{input.ConfirmWords([]string{"da"}, []string{"nyet"})}, true, nil, "[da/nyet]"},
+	} {
+		out := use(tt.in)
+		got, err := input.Confirm("Go?", tt.opts...)
+		if got != tt.want || err != tt.err {
+			t.Errorf("Confirm(%q) = %v, %v; want %v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+		if !strings.Contains(out.String(), tt.hint) {
+			t.Errorf("Confirm(%q) prompt = %q; want it to contain %q", tt.in, out.String(), tt.hint)
+		}
+	}
+
+	// A typo asks again, naming the accepted answers
+	out := use("yse\nn\n")
+	if got, err := input.Confirm("Go?", input.DefaultYes()); got || err != nil {
+		t.Errorf("Confirm after typo = %v, %v; want false, nil", got, err)
+	}
+	if !strings.Contains(out.String(), "Please answer y or n") || strings.Count(out.String(), "Go? [Y/n] ") != 2 {
+		t.Errorf("Confirm did not ask again after a typo:\n%s", out.String())
+	}
+
+	for _, tt := range []struct{ in, want string }{
+		{"r\n", "Retry"},
+		{"RETRY\n", "Retry"},
+		{"i\n", "Ignore"},
+		{"x\nabort\n", "Abort"},
+	} {
+		out := use(tt.in)
+		got, err := input.Choice("Disk not ready.", "[A]bort/[R]etry/[I]gnore")
+		if got != tt.want || err != nil {
+			t.Errorf("Choice(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+		if retry := strings.Contains(out.String(), "Please choose one of A, R, I"); retry != strings.HasPrefix(tt.in, "x") {
+			t.Errorf("Choice(%q) retry message shown = %v", tt.in, retry)
+		}
+	}
+
+	// Hotkeys may sit inside the label, and without brackets the first
+	// letter is used
+	use("x\n")
+	if got, _ := input.Choice("Pick", "E[x]it/Save"); got != "Exit" {
+		t.Errorf("Choice(E[x]it) = %q; want Exit", got)
+	}
+	use("s\n")
+	if got, _ := input.Choice("Pick", "E[x]it/Save"); got != "Save" {
+		t.Errorf("Choice(Save) = %q; want Save", got)
+	}
+
+	for _, spec := range []string{
+		"[A]bort/[a]gain", // duplicate hotkey
+		"Apple/Avocado",   // duplicate first letter
+		"[A]bort/[Retry",  // unclosed bracket
+		"[Ab]ort/Retry",   // hotkey longer than one character
+		"Abort",           // a single option
+		"Abort//Retry",    // empty option
+	} {
+		use("a\n")
+		if _, err := input.Choice("Pick", spec); err == nil {
+			t.Errorf("Choice spec %q: no error", spec)
+		}
+	}
+}
+
+// update is this test binary's own -update flag. Importing fmtpytest must
+// not redefine it.
+var update = flag.Bool("update", false, "unused; checks that fmtpytest does not define -update")
+
+func TestFmtpytestRun(t *testing.T) {
+	greet := func() {
+		name := Input("Enter name: ").String()
+		age := Input("Enter age: ").Int()
+		Print(`f"{greeting}, {name}!"`, color.Green("Hello"), name)
+		fmt.Println()
+		fmt.Fprintln(os.Stderr, "age", age)
+	}
+
+	r := fmtpytest.Run(t, greet, "Ada", "36")
+	r.ExpectPrompt("Enter name:", "Enter age:").ExpectOutput("Hello, Ada!")
+	want := "Enter name: Ada\nEnter age: 36\nage 36\n"
+	if got := r.ErrOutput(); got != want {
+		t.Errorf("ErrOutput() = %q; want prompts and answers on stderr: %q", got, want)
+	}
+	if !strings.Contains(r.RawOutput(), "\033[32m") {
+		t.Errorf("RawOutput() should keep colors, got %q", r.RawOutput())
+	}
+	if strings.Contains(r.Output(), "\033") {
+		t.Errorf("Output() should strip colors, got %q", r.Output())
+	}
+	r.MatchGolden("testdata/greeting.golden")
+
+	// FMTPYTEST_UPDATE writes golden files
+	golden := filepath.Join(t.TempDir(), "new", "greeting.golden")
+	t.Setenv("FMTPYTEST_UPDATE", "1")
+	r.MatchGolden(golden)
+	if data, err := os.ReadFile(golden); err != nil || string(data) != r.RawOutput() {
+		t.Errorf("FMTPYTEST_UPDATE=1 wrote %q, %v; want the raw output", data, err)
+	}
+	t.Setenv("FMTPYTEST_UPDATE", "")
+
+	// Missing or misordered prompts are reported
+	var rec recordingTB
+	fmtpytest.Run(&rec, greet, "Ada", "36").ExpectPrompt("Enter age:", "Enter name:")
+	if !rec.failed {
+		t.Errorf("ExpectPrompt should fail for prompts asked in another order")
+	}
+
+	// A prompt that hits the end of input was still asked
+	r = fmtpytest.Run(t, func() { input.Input("Enter name: ") }).ExpectPrompt("Enter name:")
+	if got := r.Prompts(); len(got) != 1 || got[0] != "Enter name:" {
+		t.Errorf("Prompts() = %q; want the prompt that got no answer", got)
+	}
+}
+
+// recordingTB notes failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recordingTB) Helper()                           {}
+func (r *recordingTB) Errorf(string, ...interface{})     { r.failed = true }
+func (r *recordingTB) Fatalf(f string, a ...interface{}) { panic(fmt.Sprintf(f, a...)) }
+
+func TestPromptModes(t *testing.T) {
+	for _, tt := range []struct {
+		mode input.PromptMode
+		want string
+	}{
+		{input.PromptAlways, "Name: Ada\n"}, // piped answers are echoed
+		{input.PromptTerminal, ""},          // input is not a terminal
+		{input.PromptNever, ""},
+	} {
+		var out bytes.Buffer
+		c := input.NewConsole(strings.NewReader("Ada\n"), &out)
+		c.SetPromptMode(tt.mode)
+		if got := c.Input("Name: "); got != "Ada" {
+			t.Errorf("mode %d: Input = %q; want %q", tt.mode, got, "Ada")
+		}
+		if out.String() != tt.want {
+			t.Errorf("mode %d: console output = %q; want %q", tt.mode, out.String(), tt.want)
+		}
+	}
+}
+
+func TestInputLines(t *testing.T) {
+	run := func(in string, opts ...input.LinesOption) ([]string, string, string, error) {
+		var out bytes.Buffer
+		prev := input.SetDefaultConsole(input.NewConsole(strings.NewReader(in), &out))
+		defer input.SetDefaultConsole(prev)
+		lines, text, err := input.InputLines(">>> ", opts...)
+		return lines, text, out.String(), err
+	}
+
+	lines, text, _, err := run("  indented\nsecond\nEOF\nignored\n", input.UntilLine("EOF"))
+	if err != nil || len(lines) != 2 || text != "  indented\nsecond" {
+		t.Errorf("UntilLine: got %q, %q, %v", lines, text, err)
+	}
+
+	lines, _, out, err := run("a\nb\n\nc\n", input.UntilBlank(), input.ContinuationPrompt("... "), input.LineNumbers())
+	if err != nil || len(lines) != 2 {
+		t.Errorf("UntilBlank: got %q, %v", lines, err)
+	}
+	if want := ">>>   1 │ a\n...   2 │ b\n...   3 │ \n"; out != want {
+		t.Errorf("prompts = %q; want %q", out, want)
+	}
+
+	// Without a terminator, input runs to the end
+	if lines, _, _, err := run("a\n\nb"); err != nil || len(lines) != 3 {
+		t.Errorf("EOF: got %q, %v", lines, err)
+	}
+	if _, _, _, err := run(""); err != io.EOF {
+		t.Errorf("empty input: err = %v; want io.EOF", err)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor is a shell script")
+	}
+	dir := t.TempDir()
+	editor := func(name, script string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// The editor sees the initial text in a file with the chosen extension
+	t.Setenv("VISUAL", editor("append", `case "$1" in *.md) ;; *) exit 1;; esac; printf 'Fix parser\n\n' >> "$1"`))
+	got, err := input.Edit("# Describe the change\n", input.EditExtension("md"), input.EditStripComments())
+	if err != nil || got != "Fix parser" {
+		t.Errorf("Edit = %q, %v; want %q", got, err, "Fix parser")
+	}
+
+	if _, err := input.Edit("draft\n", input.EditCommand(editor("keep", "exit 0"))); err != input.ErrEditUnchanged {
+		t.Errorf("unchanged text: err = %v; want ErrEditUnchanged", err)
+	}
+	if _, err := input.Edit("# only comments\n", input.EditCommand(editor("keep", "exit 0")), input.EditStripComments()); err != input.ErrEditEmpty {
+		t.Errorf("empty text: err = %v; want ErrEditEmpty", err)
+	}
+	if _, err := input.Edit("x", input.EditCommand(editor("fail", "exit 3"))); err == nil {
+		t.Errorf("a failing editor should return an error")
+	}
+
+	// Like git, the command is run by the shell, so quoted paths and
+	// arguments work
+	spaced := editor("my editor", `printf '%s\n' "$1" >> "$2"`)
+	got, err = input.Edit("", input.EditCommand(`'`+spaced+`' "two words"`))
+	if err != nil || got != "two words" {
+		t.Errorf("quoted editor command: Edit = %q, %v; want %q", got, err, "two words")
+	}
+}
+
+func TestValueResolver(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "app.json")
+	if err := os.WriteFile(config, []byte(`{"name": "from-config", "port": 8080}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := input.NewConsole(strings.NewReader(""), io.Discard)
+	prev := input.SetDefaultConsole(c)
+	defer input.SetDefaultConsole(prev)
+
+	resolver := func(args ...string) *input.Resolver {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.String("name", "flag-default", "")
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return input.Value("name").Flag(fs).Env("APP_NAME").ConfigFile(config).Prompt("Name: ")
+	}
+
+	t.Setenv("APP_NAME", "from-env")
+	for _, tt := range []struct {
+		args []string
+		want string
+		from string
+	}{
+		{[]string{"--name", "from-flag"}, "from-flag", "flag --name"},
+		{nil, "from-env", "env APP_NAME"}, // an unset flag's default is ignored
+	} {
+		r := resolver(tt.args...)
+		v, err := r.Get()
+		if err != nil || v.String() != tt.want || r.From() != tt.from {
+			t.Errorf("Get() = %q, %v from %q; want %q from %q", v, err, r.From(), tt.want, tt.from)
+		}
+	}
+
+	t.Setenv("APP_NAME", "")
+	r := resolver()
+	if v, _ := r.Get(); v != "from-config" || r.Source() != input.SourceConfig {
+		t.Errorf("config: got %q from %v", v, r.Source())
+	}
+	if port, _ := input.Value("port").ConfigFile(config).Get(); port.Int() != 8080 {
+		t.Errorf("config numbers should resolve, got %q", port)
+	}
+
+	c.SetAnswers("", "typed")
+	r = input.Value("user").Prompt("User: ")
+	if v, err := r.Get(); v != "typed" || err != nil || r.Source() != input.SourcePrompt {
+		t.Errorf("prompt: got %q, %v from %v", v, err, r.Source())
+	}
+
+	// Non-interactive mode never prompts
+	c.SetNonInteractive(true)
+	r = input.Value("user").Env("APP_USER").Prompt("User: ")
+	_, err := r.Get()
+	if !errors.Is(err, input.ErrNonInteractive) || !strings.Contains(err.Error(), "APP_USER") {
+		t.Errorf("non-interactive: err = %v; want ErrNonInteractive naming APP_USER", err)
+	}
+	if v, err := r.Default("guest").Get(); v != "guest" || err != nil || r.Source() != input.SourceDefault {
+		t.Errorf("default: got %q, %v from %v", v, err, r.Source())
+	}
+	if _, err := input.Value("token").Get(); !errors.Is(err, input.ErrNoValue) {
+		t.Errorf("no sources: err = %v; want ErrNoValue", err)
+	}
+
+	// The config file is read by Get, not when the chain is built, and only
+	// when no earlier source has the value
+	later := filepath.Join(dir, "later.json")
+	r = input.Value("name").Env("APP_NAME").ConfigFile(later)
+	if err := os.WriteFile(later, []byte(`{"name": "written-later"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := r.Get(); v != "written-later" || err != nil {
+		t.Errorf("config written after ConfigFile: got %q, %v", v, err)
+	}
+	if err := os.WriteFile(later, []byte(`{broken`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get(); err == nil || !strings.Contains(err.Error(), "later.json") {
+		t.Errorf("malformed config: err = %v; want it to name the file", err)
+	}
+	t.Setenv("APP_NAME", "from-env")
+	if v, err := r.Get(); v != "from-env" || err != nil {
+		t.Errorf("env before a malformed config: got %q, %v", v, err)
+	}
+}
+
+func TestInputDecoding(t *testing.T) {
+	read := func(data string, setup func(*input.Console)) ([]string, error) {
+		c := input.NewConsole(strings.NewReader(data), io.Discard)
+		if setup != nil {
+			setup(c)
+		}
+		var lines []string
+		for {
+			line, err := c.ReadLine("")
+			if err == io.EOF {
+				return lines, nil
+			}
+			if err != nil {
+				return lines, err
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		data  string
+		setup func(*input.Console)
+		want  []string
+	}{
+		{"BOM and CRLF", "\xef\xbb\xbfAda\r\n36\r\n", nil, []string{"Ada", "36"}},
+		{"lone CR", "one\rtwo\r", nil, []string{"one", "two"}},
+		{"replace", "caf\xe9\n", nil, []string{"caf�"}},
+		{"escape", "caf\xe9\n", func(c *input.Console) { c.SetInvalidUTF8(input.InvalidEscape) }, []string{`caf\xe9`}},
+		{"latin1", "caf\xe9\n", func(c *input.Console) { c.SetEncoding(input.Latin1) }, []string{"café"}},
+		{"windows-1252", "\x93quoted\x94 \x80\n", func(c *input.Console) { c.SetEncoding(input.Windows1252) }, []string{"“quoted” €"}},
+		{"UTF-16LE with BOM", "\xff\xfeh\x00i\x00\r\x00\n\x00=\xd8\x00\xde\n\x00", nil, []string{"hi", "😀"}},
+		{"UTF-16BE", "\x00h\x00i\x00\n", func(c *input.Console) { c.SetEncoding(input.UTF16BE) }, []string{"hi"}},
+	}
+	for _, tt := range tests {
+		got, err := read(tt.data, tt.setup)
+		if err != nil || strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	// A line ending in "\r" returns without waiting for more input, and a
+	// "\n" arriving later is not read as an empty line
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	pc := input.NewConsole(pr, io.Discard)
+	go pw.Write([]byte("one\r"))
+	done := make(chan string)
+	go func() {
+		line, _ := pc.ReadLine("")
+		done <- line
+	}()
+	select {
+	case line := <-done:
+		if line != "one" {
+			t.Errorf("line before a lone CR = %q; want %q", line, "one")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReadLine blocked after a lone CR")
+	}
+	go pw.Write([]byte("\ntwo\n"))
+	if line, err := pc.ReadLine(""); line != "two" || err != nil {
+		t.Errorf("line after a split CRLF = %q, %v; want %q", line, err, "two")
+	}
+
+	_, err := read("caf\xe9\n", func(c *input.Console) { c.SetInvalidUTF8(input.InvalidError) })
+	if err != input.ErrInvalidUTF8 {
+		t.Errorf("InvalidError: err = %v; want ErrInvalidUTF8", err)
+	}
+	// Answer files saved on Windows keep working
+	path := filepath.Join(t.TempDir(), "answers.json")
+	if err := os.WriteFile(path, []byte("\xef\xbb\xbf{\"Name:\": \"Ada\"}\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := input.NewConsole(strings.NewReader(""), io.Discard)
+	if err := c.LoadAnswers(path); err != nil || c.Input("Name: ") != "Ada" {
+		t.Errorf("LoadAnswers with a BOM failed: %v", err)
+	}
+
+	if enc, err := input.ParseEncoding("UTF-16_LE"); err != nil || enc != input.UTF16LE {
+		t.Errorf("ParseEncoding = %v, %v", enc, err)
+	}
+}
+
+func TestErrInterrupted(t *testing.T) {
+	if !errors.Is(input.ErrInterrupted, input.ErrAborted) {
+		t.Errorf("ErrInterrupted should match ErrAborted for code handling cancelled prompts")
+	}
+	if errors.Is(input.ErrAborted, input.ErrInterrupted) {
+		t.Errorf("ErrAborted is not an interrupt")
+	}
+	if input.ErrInterrupted.Error() != "input: interrupted" {
+		t.Errorf("ErrInterrupted.Error() = %q", input.ErrInterrupted.Error())
+	}
+}
+
+// unsetenv removes an environment variable until the test ends. An empty
+// value is not enough for variables like FORCE_COLOR, which count when set.
+func unsetenv(t *testing.T, key string) {
+	t.Setenv(key, "") // restores the variable after the test
+	os.Unsetenv(key)
+}
+
+func TestDetectProfile(t *testing.T) {
+	vars := []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE", "COLORTERM", "TERM", "WT_SESSION"}
+	var buf bytes.Buffer // not a terminal
+	tests := []struct {
+		env  map[string]string
+		want color.Profile
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, color.ProfileNone},
+		{map[string]string{"FORCE_COLOR": ""}, color.Profile16},
+		{map[string]string{"FORCE_COLOR": "1"}, color.Profile16},
+		{map[string]string{"FORCE_COLOR": "2"}, color.Profile256},
+		{map[string]string{"FORCE_COLOR": "3"}, color.ProfileTrueColor},
+		{map[string]string{"FORCE_COLOR": "0", "CLICOLOR_FORCE": "1"}, color.ProfileNone},
+		{map[string]string{"FORCE_COLOR": "1", "COLORTERM": "truecolor"}, color.ProfileTrueColor},
+		{map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, color.ProfileNone},
+		{map[string]string{"CLICOLOR_FORCE": "1", "TERM": "xterm-256color"}, color.Profile256},
+		{map[string]string{"CLICOLOR_FORCE": "1", "TERM": "xterm-direct"}, color.ProfileTrueColor},
+		{map[string]string{"CLICOLOR_FORCE": "1", "TERM": "dumb"}, color.Profile16},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.env), func(t *testing.T) {
+			for _, key := range vars {
+				unsetenv(t, key)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := color.DetectProfile(&buf); got != tt.want {
+				t.Errorf("DetectProfile with %v = %v; want %v", tt.env, got, tt.want)
+			}
+		})
+	}
+
+	prev := color.SetDefaultProfile(color.ProfileNone)
+	defer color.SetDefaultProfile(prev)
+	if got := color.Red("x"); got != "x" {
+		t.Errorf("Red with ProfileNone = %q; want plain text", got)
+	}
+	color.SetDefaultProfile(color.Profile16)
+	if got := color.Red("x"); got != "\033[31mx\033[0m" {
+		t.Errorf("Red with Profile16 = %q", got)
+	}
+}
+
+func TestExtendedColors(t *testing.T) {
+	prev := color.SetDefaultProfile(color.ProfileTrueColor)
+	defer color.SetDefaultProfile(prev)
+
+	tests := []struct {
+		profile color.Profile
+		c       *color.Color
+		want    string
+	}{
+		{color.ProfileTrueColor, color.RGB(255, 136, 0), "\033[38;2;255;136;0mx\033[0m"},
+		{color.ProfileTrueColor, color.BgHex("#f80"), "\033[48;2;255;136;0mx\033[0m"},
+		{color.ProfileTrueColor, color.HSL(120, 1, 0.25), "\033[38;2;0;128;0mx\033[0m"},
+		{color.ProfileTrueColor, color.Ansi256(208), "\033[38;5;208mx\033[0m"},
+		{color.Profile256, color.RGB(255, 136, 0), "\033[38;5;208mx\033[0m"},
+		{color.Profile256, color.BgRGB(128, 128, 128), "\033[48;5;244mx\033[0m"},
+		{color.Profile16, color.RGB(250, 10, 10), "\033[91mx\033[0m"},
+		{color.Profile16, color.BgAnsi256(4), "\033[44mx\033[0m"},
+		{color.Profile16, color.Ansi256(21), "\033[34mx\033[0m"},
+		{color.ProfileNone, color.RGB(1, 2, 3), "x"},
+		{color.ProfileTrueColor, color.New(color.Bold).AddRGB(1, 2, 3), "\033[1;38;2;1;2;3mx\033[0m"},
+		{color.ProfileTrueColor, color.Hex("not a color"), "x"},
+	}
+	for i, tt := range tests {
+		color.SetDefaultProfile(tt.profile)
+		if got := tt.c.Sprint("x"); got != tt.want {
+			t.Errorf("%d: %v profile: got %q; want %q", i, tt.profile, got, tt.want)
+		}
+	}
+
+	if rgb, err := color.ParseHex("#FF8800"); err != nil || rgb != (color.RGBColor{R: 255, G: 136}) || rgb.Hex() != "#ff8800" {
+		t.Errorf("ParseHex = %v, %v", rgb, err)
+	}
+	if _, err := color.ParseHex("#12345"); err == nil {
+		t.Errorf("ParseHex should reject 5 digits")
+	}
+}
+
+func TestColorMath(t *testing.T) {
+	coral, ok := color.LookupNamed("Coral")
+	if !ok || coral.Hex() != "#ff7f50" {
+		t.Errorf("LookupNamed(Coral) = %v, %v", coral, ok)
+	}
+	if c := color.Named("dark-olive green"); c.Hex() != "#556b2f" {
+		t.Errorf("Named(dark-olive green) = %s", c.Hex())
+	}
+	if _, ok := color.LookupNamed("nope"); ok {
+		t.Errorf("LookupNamed(nope) should fail")
+	}
+
+	red := color.RGBColor{R: 255}
+	white := color.RGBColor{R: 255, G: 255, B: 255}
+	black := color.RGBColor{}
+	tests := []struct {
+		name string
+		got  color.RGBColor
+		want string
+	}{
+		{"Lighten", red.Lighten(0.25), "#ff8080"},
+		{"Darken", red.Darken(0.25), "#800000"},
+		{"Saturate", color.RGBColor{R: 191, G: 64, B: 64}.Saturate(-1), "#808080"},
+		{"Mix", red.Mix(white, 0.5), "#ff8080"},
+		{"Complement", red.Complement(), "#00ffff"},
+		{"Invert", coral.Invert(), "#0080af"},
+		{"FromHSV", color.FromHSV(210, 1, 1), "#0080ff"},
+		{"FromLab", color.FromLab(coral.Lab()), "#ff7f50"},
+	}
+	for _, tt := range tests {
+		if tt.got.Hex() != tt.want {
+			t.Errorf("%s = %s; want %s", tt.name, tt.got.Hex(), tt.want)
+		}
+	}
+
+	if h, s, l := coral.HSL(); math.Abs(h-16.1) > 0.1 || s != 1 || math.Abs(l-0.657) > 0.001 {
+		t.Errorf("HSL = %v, %v, %v", h, s, l)
+	}
+	if h, s, v := coral.HSV(); color.FromHSV(h, s, v) != coral {
+		t.Errorf("HSV round trip = %v, %v, %v", h, s, v)
+	}
+	if r := color.ContrastRatio(white, black); math.Abs(r-21) > 1e-9 {
+		t.Errorf("ContrastRatio(white, black) = %v", r)
+	}
+	if fg := color.BestForeground(color.Named("gold")); fg != black {
+		t.Errorf("BestForeground(gold) = %s", fg.Hex())
+	}
+	if fg := color.BestForeground(color.Named("navy"), coral, black); fg != coral {
+		t.Errorf("BestForeground(navy) = %s", fg.Hex())
+	}
+}
+
+func TestColorNesting(t *testing.T) {
+	prev := color.SetDefaultProfile(color.Profile16)
+	defer color.SetDefaultProfile(prev)
+
+	got := color.OnYellow("a " + color.Red("b") + " c")
+	want := "\033[43ma \033[31mb\033[0m\033[43m c\033[0m"
+	if got != want {
+		t.Errorf("nested = %q; want %q", got, want)
+	}
+
+	// A reset at the end needs no re-emitted style
+	got = color.New(color.Bold).Sprint(color.Red("x"))
+	if want := "\033[1m\033[31mx\033[0m\033[0m"; got != want {
+		t.Errorf("trailing reset = %q; want %q", got, want)
+	}
+
+	got = color.New(color.Bold).Sprint("a " + color.New(color.ResetBold).Sprint("b") + "\033[m c")
+	want = "\033[1ma \033[22mb\033[0m\033[1m\033[m\033[1m c\033[0m"
+	if got != want {
+		t.Errorf("unset = %q; want %q", got, want)
+	}
+}
+
+func BenchmarkColorRed(b *testing.B) {
+	prev := color.SetDefaultProfile(color.Profile16)
+	defer color.SetDefaultProfile(prev)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = color.Red("error message")
+	}
+}
+
+func BenchmarkColorShortMethod(b *testing.B) {
+	prev := color.SetDefaultProfile(color.Profile16)
+	defer color.SetDefaultProfile(prev)
+	c := color.Get()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = c.BR("error message")
+	}
+}
+
+func BenchmarkColorStyleSprint(b *testing.B) {
+	prev := color.SetDefaultProfile(color.ProfileTrueColor)
+	defer color.SetDefaultProfile(prev)
+	style := color.New(color.Bold, color.Underline).AddRGB(255, 136, 0).AddBgRGB(20, 20, 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = style.Sprint("error message")
+	}
+}
+
+func BenchmarkColorNested(b *testing.B) {
+	prev := color.SetDefaultProfile(color.Profile16)
+	defer color.SetDefaultProfile(prev)
+	inner := color.Red("b")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = color.OnYellow("a " + inner + " c")
+	}
+}
+
+func TestColorPrint(t *testing.T) {
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE"} {
+		unsetenv(t, key)
+	}
+
+	warn := color.New(color.Bold, color.FgYellow)
+	res := fmtpytest.Run(t, func() {
+		warn.Print("a", 1)
+		warn.Printf(" %03d", 7)
+		warn.Println(" b")
+		warn.PrintfFunc()("%s!\n", "c")
+	})
+	want := "\033[1;33ma1\033[0m\033[1;33m 007\033[0m\033[1;33m b\033[0m\n\033[1;33mc!\n\033[0m"
+	if got := res.RawOutput(); got != want {
+		t.Errorf("stdout = %q; want %q", got, want)
+	}
+
+	// Other writers get their own detection: a buffer is not a terminal
+	var buf bytes.Buffer
+	warn.Fprintln(&buf, "plain", 2)
+	warn.Fprintf(&buf, "%d", 3)
+	if got := buf.String(); got != "plain 2\n3" {
+		t.Errorf("buffer = %q", got)
+	}
+	t.Setenv("FORCE_COLOR", "1")
+	buf.Reset()
+	warn.Fprint(&buf, "x")
+	if got := buf.String(); got != "\033[1;33mx\033[0m" {
+		t.Errorf("forced buffer = %q", got)
+	}
+
+	prev := color.SetDefaultProfile(color.Profile16)
+	defer color.SetDefaultProfile(prev)
+	if got := warn.SprintFunc()("x", 1); got != "\033[1;33mx1\033[0m" {
+		t.Errorf("SprintFunc = %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	prev := color.SetDefaultProfile(color.ProfileTrueColor)
+	defer color.SetDefaultProfile(prev)
+
+	tests := []struct {
+		markup string
+		args   []interface{}
+		want   string
+	}{
+		{"[bold red]Error[/] {x}", []interface{}{42}, "\033[1;31mError\033[0m 42"},
+		{"[green]{name}[/]", []interface{}{"[red]ann"}, "\033[32m[red]ann\033[0m"},
+		{"[bold on white]a [red]b[/] c", nil, "\033[1;47ma \033[0m\033[1;47;31mb\033[0m\033[1;47m c\033[0m"},
+		{"[#ff8800]o[/#ff8800] [on coral]c", nil, "\033[38;2;255;136;0mo\033[0m \033[48;2;255;127;80mc\033[0m"},
+		{"[bright_red]a[/bright_red]", nil, "\033[91ma\033[0m"},
+		{"[red]a [italic]b[/red] c", nil, "\033[31ma \033[0m\033[31;3mb\033[0m c"},
+		{"[#102030]a [blue]b", nil, "\033[38;2;16;32;48ma \033[0m\033[34mb\033[0m"},
+		{`\[not a tag] {}`, nil, "[not a tag] {}"},
+		{"[error]x[/] [success]y[/]", nil, "\033[1;31mx\033[0m \033[1;32my\033[0m"},
+	}
+	for _, tt := range tests {
+		got, err := color.Render(tt.markup, tt.args...)
+		if err != nil || got != tt.want {
+			t.Errorf("Render(%q) = %q, %v; want %q", tt.markup, got, err, tt.want)
+		}
+	}
+
+	for _, markup := range []string{"[blod]x", "[red on]x", "[/]", "[red]x[/blue]", "[red", "[]", "[#12]x"} {
+		if _, err := color.Render(markup); err == nil {
+			t.Errorf("Render(%q) should fail", markup)
+		}
+	}
+
+	// Values are formatted by the caller; a format spec is not ignored silently
+	if _, err := color.Render("[green]{total:.2f}[/]", 3.14159); err == nil || !strings.Contains(err.Error(), "{total:.2f}") {
+		t.Errorf("Render with a format spec: err = %v; want an error naming the placeholder", err)
+	}
+
+	color.SetDefaultProfile(color.ProfileNone)
+	if got := color.MustRender("[bold]{}[/]!", "hi"); got != "hi!" {
+		t.Errorf("MustRender without colors = %q", got)
+	}
+}
+
+func TestTheme(t *testing.T) {
+	prevProfile := color.SetDefaultProfile(color.Profile16)
+	defer color.SetDefaultProfile(prevProfile)
+	dark, _ := color.BuiltinTheme("dark")
+	prev := color.SetTheme(dark)
+	defer color.SetTheme(prev)
+
+	var c color.Color
+	if got := c.I("{n} files", 3); got != "\033[36m3 files\033[0m" {
+		t.Errorf("dark I = %q", got)
+	}
+	if got := color.InfoText("x"); got != c.I("x") {
+		t.Errorf("InfoText = %q and I = %q should agree", got, c.I("x"))
+	}
+
+	for _, name := range color.ThemeNames() {
+		theme, ok := color.BuiltinTheme(name)
+		if !ok || theme.Error == nil || theme.Code == nil {
+			t.Errorf("BuiltinTheme(%q) = %v, %v", name, theme, ok)
+		}
+	}
+
+	theme, err := color.ParseTheme([]byte(`{"base": "high-contrast", "error": "Bold  White on #B31D28", "code": "italic"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	color.SetTheme(theme)
+	if got := color.ErrorText("e"); got != "\033[1;37;41me\033[0m" {
+		t.Errorf("custom ErrorText = %q", got)
+	}
+	if got := color.MustRender("[code]x[/] [warning]w"); got != "\033[3mx\033[0m \033[1;30;103mw\033[0m" {
+		t.Errorf("themed markup = %q", got)
+	}
+
+	for _, data := range []string{`{"base": "neon"}`, `{"errors": "red"}`, `{"error": "blod"}`, `[`} {
+		if _, err := color.ParseTheme([]byte(data)); err == nil {
+			t.Errorf("ParseTheme(%s) should fail", data)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "theme.json")
+	os.WriteFile(path, []byte(`{"base": "light", "muted": "dim"}`), 0o644)
+	loaded, err := color.LoadTheme(path)
+	if err != nil || loaded.Muted.Sprint("m") != "\033[2mm\033[0m" {
+		t.Errorf("LoadTheme = %v, %v", loaded, err)
+	}
+
+	t.Setenv("FMTPY_THEME", "colorblind")
+	color.SetTheme(nil)
+	if got := color.SuccessText("ok"); got != "\033[1;94mok\033[0m" {
+		t.Errorf("colorblind SuccessText = %q", got)
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	prev := color.SetDefaultProfile(color.Profile16)
+	defer color.SetDefaultProfile(prev)
+
+	red := color.Red("word")
+	link := "\x1b]8;;https://example.com\x1b\\site\x1b]8;;\x1b\\"
+	tests := []struct {
+		s     string
+		strip string
+		width int
+	}{
+		{red, "word", 4},
+		{link, "site", 4},
+		{"日本語", "日本語", 6},
+		{"été", "été", 3},
+		{"🎉!", "🎉!", 3},
+		{"\x1b[38;2;1;2;3mx\x1b[0m\x1b[", "x", 1},
+		{"a\x1b[31\x07b", "a\x07b", 2},
+	}
+	for _, tt := range tests {
+		if got := color.Strip(tt.s); got != tt.strip {
+			t.Errorf("Strip(%q) = %q; want %q", tt.s, got, tt.strip)
+		}
+		if got := color.VisibleWidth(tt.s); got != tt.width {
+			t.Errorf("VisibleWidth(%q) = %d; want %d", tt.s, got, tt.width)
+		}
+	}
+
+	truncs := []struct {
+		s     string
+		width int
+		tail  string
+		want  string
+	}{
+		{color.Red("a long message"), 8, "…", "\033[31ma long …\033[0m"},
+		{red, 4, "…", red},
+		{"日本語テキスト", 7, "…", "日本語…"},
+		{link + " more", 3, "…", "\x1b]8;;https://example.com\x1b\\si…\x1b]8;;\x1b\\"},
+		{color.Red("ab") + "cdef", 3, "…", "\033[31mab\033[0m…"},
+		{"abcdef", 2, "...", ".."},
+	}
+	for _, tt := range truncs {
+		if got := color.Truncate(tt.s, tt.width, tt.tail); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q; want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+
+	if got := input.Center(red, 8); got != "  "+red+"  " {
+		t.Errorf("Center(red) = %q", got)
+	}
+	if got := input.LeftPad("日本", 5, "0"); got != "0日本" {
+		t.Errorf("LeftPad(日本) = %q", got)
+	}
+	if got := input.RightPad(red, 7, "全"); got != red+"全 " {
+		t.Errorf("RightPad(red, 全) = %q", got)
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	prev := color.SetDefaultProfile(color.ProfileTrueColor)
+	defer color.SetDefaultProfile(prev)
+
+	red := color.ColorValue{Mode: color.ColorBasic, Index: 1}
+	spans := color.Parse("a" + color.New(color.Bold).Sprint("b"+color.Red("c")+"d") + "\x1b]8;;https://x.dev\x07e\x1b]8;;\x07")
+	want := []color.Span{
+		{Text: "a"},
+		{Text: "b", Style: color.Style{Bold: true}},
+		{Text: "c", Style: color.Style{Bold: true, Fg: red}},
+		{Text: "d", Style: color.Style{Bold: true}},
+		{Text: "e", Style: color.Style{Link: "https://x.dev"}},
+	}
+	if fmt.Sprint(spans) != fmt.Sprint(want) {
+		t.Errorf("Parse = %+v\nwant %+v", spans, want)
+	}
+
+	styles := []struct {
+		seq  string
+		want color.Style
+	}{
+		{"\x1b[38;5;208;48;2;1;2;3m", color.Style{
+			Fg: color.ColorValue{Mode: color.ColorPalette, Index: 208},
+			Bg: color.ColorValue{Mode: color.ColorRGB, RGB: color.RGBColor{R: 1, G: 2, B: 3}}}},
+		{"\x1b[38:2::10:20:30;4:3m", color.Style{Underline: true,
+			Fg: color.ColorValue{Mode: color.ColorRGB, RGB: color.RGBColor{R: 10, G: 20, B: 30}}}},
+		{"\x1b[1;2;3;22;93;104m", color.Style{Italic: true,
+			Fg: color.ColorValue{Mode: color.ColorBasic, Index: 11},
+			Bg: color.ColorValue{Mode: color.ColorBasic, Index: 12}}},
+		{"\x1b[31;39;1;0;3m", color.Style{Italic: true}},
+		{"\x1b[38;5;999;1m", color.Style{Bold: true}},
+		{"\x1b[38;2;1;2m", color.Style{}},
+		{"\x1b[?;1m", color.Style{Bold: true}},
+		{"\x1b[1m\x1b[2J", color.Style{Bold: true}},
+	}
+	for _, tt := range styles {
+		spans := color.Parse(tt.seq + "x")
+		if len(spans) == 0 || spans[len(spans)-1].Style != tt.want {
+			t.Errorf("Parse(%q) = %+v; want style %+v", tt.seq, spans, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "\x1b", "\x1b[", "\x1b]8;;unterminated", "x\x1b[31", "\x1b[\x1b[31mx"} {
+		for _, span := range color.Parse(s) {
+			if strings.Contains(span.Text, "\x1b") {
+				t.Errorf("Parse(%q) kept an escape in %q", s, span.Text)
+			}
+		}
+	}
+
+	// Rendering spans back gives text that parses to the same spans
+	text := color.MustRender("[bold #ff8800 on blue]a [italic]b[/] c[/] d") + "\x1b]8;;https://x.dev\x1b\\e\x1b]8;;\x1b\\"
+	spans = color.Parse(text)
+	if again := color.Parse(color.RenderSpans(spans)); fmt.Sprint(again) != fmt.Sprint(spans) {
+		t.Errorf("round trip = %+v\nwant %+v", again, spans)
+	}
+
+	color.SetDefaultProfile(color.Profile256)
+	if got := color.RenderSpans(color.Parse("\x1b[1;38;2;255;136;0mx\x1b[0my")); got != "\033[1;38;5;208mx\033[0my" {
+		t.Errorf("RenderSpans at 256 colors = %q", got)
+	}
+	if w := (color.Span{Text: "日本a"}).Width(); w != 5 {
+		t.Errorf("Span.Width = %d", w)
+	}
+}
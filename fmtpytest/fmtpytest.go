@@ -0,0 +1,200 @@
+// Package fmtpytest helps test programs that use fmtpy for input and output.
+//
+// Run executes a function with scripted input lines, captures everything it
+// writes to stdout and stderr, and records the prompts it asked:
+//
+//	func TestGreeting(t *testing.T) {
+//	    r := fmtpytest.Run(t, greet, "Ada", "36")
+//	    r.ExpectPrompt("Enter name:", "Enter age:")
+//	    r.ExpectOutput("Hello, Ada")
+//	    r.MatchGolden("testdata/greeting.golden")
+//	}
+//
+// Golden files hold the raw, colored output. Run the tests with
+// -fmtpytest.update, or with FMTPYTEST_UPDATE=1 in the environment, to write
+// them.
+package fmtpytest
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grandpaej/fmtpy/v2/color"
+	"github.com/grandpaej/fmtpy/v2/input"
+)
+
+// updateFlag has a package prefix so it cannot clash with an -update flag
+// of the test binary
+var updateFlag = flag.Bool("fmtpytest.update", false, "update fmtpytest golden files")
+
+// updating reports whether golden files should be written
+func updating() bool {
+	return *updateFlag || os.Getenv("FMTPYTEST_UPDATE") == "1"
+}
+
+// runMu serializes Run calls, since they replace process-wide stdout and stderr
+var runMu sync.Mutex
+
+// Result holds what a function did during Run
+type Result struct {
+	t       testing.TB
+	stdout  string
+	stderr  string
+	prompts []string
+}
+
+// Run calls fn with the default console answering prompts from inputs, one
+// line per prompt, and captures stdout and stderr. Prompts go to stderr, as
+// they do outside tests, with each answer echoed after its prompt. Colors
+// stay enabled so styled output can be checked. When inputs run out,
+// prompts see the end of input.
+func Run(t testing.TB, fn func(), inputs ...string) *Result {
+	t.Helper()
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("fmtpytest: %v", err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("fmtpytest: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); io.Copy(&stdout, stdoutR) }()
+	go func() { defer wg.Done(); io.Copy(&stderr, stderrR) }()
+
+	var record bytes.Buffer
+	console := input.NewConsole(strings.NewReader(""), stderrW)
+	console.SetAnswers(inputs...)
+	console.Record(&record)
+
+	origStdout, origStderr, origNoColor := os.Stdout, os.Stderr, color.NoColor
+	prevConsole := input.SetDefaultConsole(console)
+	prevProfile := color.SetDefaultProfile(color.ProfileTrueColor)
+	os.Stdout, os.Stderr, color.NoColor = stdoutW, stderrW, false
+
+	func() {
+		defer func() {
+			os.Stdout, os.Stderr, color.NoColor = origStdout, origStderr, origNoColor
+			input.SetDefaultConsole(prevConsole)
+			color.SetDefaultProfile(prevProfile)
+			stdoutW.Close()
+			stderrW.Close()
+			wg.Wait()
+		}()
+		fn()
+	}()
+
+	return &Result{
+		t:       t,
+		stdout:  stdout.String(),
+		stderr:  stderr.String(),
+		prompts: recordedPrompts(record.String()),
+	}
+}
+
+// recordedPrompts returns the prompts of a console recording. Answers that
+// look like comments are escaped by the recorder, so every "# " line is a
+// prompt.
+func recordedPrompts(s string) []string {
+	var prompts []string
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for sc.Scan() {
+		if line := sc.Text(); strings.HasPrefix(line, "# ") {
+			prompts = append(prompts, strings.TrimPrefix(line, "# "))
+		}
+	}
+	return prompts
+}
+
+// Output returns stdout with ANSI escape sequences removed
+func (r *Result) Output() string {
+	return color.Strip(r.stdout)
+}
+
+// RawOutput returns stdout exactly as written, including colors
+func (r *Result) RawOutput() string {
+	return r.stdout
+}
+
+// ErrOutput returns stderr with ANSI escape sequences removed
+func (r *Result) ErrOutput() string {
+	return color.Strip(r.stderr)
+}
+
+// RawErrOutput returns stderr exactly as written
+func (r *Result) RawErrOutput() string {
+	return r.stderr
+}
+
+// Prompts returns the prompts that were asked, in order, without
+// surrounding whitespace
+func (r *Result) Prompts() []string {
+	return r.prompts
+}
+
+// ExpectPrompt checks that the given prompts were asked in this order.
+// Each one must be contained in an asked prompt; other prompts may come in
+// between.
+func (r *Result) ExpectPrompt(prompts ...string) *Result {
+	r.t.Helper()
+	next := 0
+	for _, asked := range r.prompts {
+		if next < len(prompts) && strings.Contains(asked, prompts[next]) {
+			next++
+		}
+	}
+	if next < len(prompts) {
+		r.t.Errorf("fmtpytest: prompt %q was not asked in the expected order\nprompts asked: %q", prompts[next], r.prompts)
+	}
+	return r
+}
+
+// ExpectOutput checks that the ANSI-stripped stdout contains each of want
+func (r *Result) ExpectOutput(want ...string) *Result {
+	r.t.Helper()
+	out := r.Output()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			r.t.Errorf("fmtpytest: output does not contain %q\noutput:\n%s", w, out)
+		}
+	}
+	return r
+}
+
+// MatchGolden compares the raw stdout, colors included, with the golden
+// file at path. With -fmtpytest.update or FMTPYTEST_UPDATE=1 the file is
+// written instead.
+func (r *Result) MatchGolden(path string) *Result {
+	r.t.Helper()
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("fmtpytest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(r.stdout), 0o644); err != nil {
+			r.t.Fatalf("fmtpytest: %v", err)
+		}
+		return r
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("fmtpytest: %v (run with -fmtpytest.update to create it)", err)
+	}
+	if string(want) != r.stdout {
+		r.t.Errorf("fmtpytest: output does not match %s (run with -fmtpytest.update to accept)\ngot:\n%q\nwant:\n%q", path, r.stdout, want)
+	}
+	return r
+}
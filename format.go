@@ -0,0 +1,153 @@
+package fmtpy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/grandpaej/fmtpy/v2/input"
+)
+
+// placeholderSpecs returns the format spec of each {name:spec} placeholder
+// in an f-string template, "" for placeholders without one. Placeholders
+// are found the same way as in convertPythonStyle.
+func placeholderSpecs(s string) []string {
+	var specs []string
+	start := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			if start >= 0 {
+				specs = append(specs, "")
+			}
+			start = i + 1
+		case '}':
+			if start >= 0 {
+				_, spec, _ := strings.Cut(s[start:i], ":")
+				specs = append(specs, spec)
+				start = -1
+			}
+		}
+	}
+	if start >= 0 {
+		specs = append(specs, "")
+	}
+	return specs
+}
+
+// applySpecs formats each argument whose placeholder has the locale spec
+// {total:n} or {total:.2n}, and returns the new argument list
+func applySpecs(template string, args []interface{}) []interface{} {
+	out := append([]interface{}{}, args...)
+	for i, spec := range placeholderSpecs(template) {
+		if i >= len(out) {
+			break
+		}
+		if s, ok := formatSpec(out[i], spec); ok {
+			out[i] = s
+		}
+	}
+	return out
+}
+
+// formatSpec formats a number for the spec "n", or ".4n" with a precision,
+// using the separators of input.CurrentLocale. Like Python, integers are
+// written in full and floats follow the "g" type: the precision counts
+// significant digits, 6 by default, and large or small values switch to
+// exponent notation. It reports false for other specs, for values that are
+// not numbers and for integers with a precision, which Python rejects.
+func formatSpec(v interface{}, spec string) (string, bool) {
+	digits, ok := strings.CutSuffix(spec, "n")
+	if !ok {
+		return "", false
+	}
+	prec := 6
+	if digits != "" {
+		p, err := strconv.Atoi(strings.TrimPrefix(digits, "."))
+		if err != nil || digits[0] != '.' || p < 0 {
+			return "", false
+		}
+		prec = max(p, 1)
+	}
+
+	loc := input.CurrentLocale()
+	if mag, neg, isInt := toInteger(v); isInt {
+		if digits != "" {
+			return "", false
+		}
+		out := loc.FormatUint(mag)
+		if neg {
+			out = "-" + out
+		}
+		return out, true
+	}
+	f, isNum := toFloat(v)
+	if !isNum {
+		return "", false
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprint(f), true
+	}
+
+	// Localize the mantissa of the "g" form, keeping its digits
+	mantissa, exp, sci := strings.Cut(strconv.FormatFloat(f, 'g', prec, 64), "e")
+	_, frac, _ := strings.Cut(mantissa, ".")
+	m, _ := strconv.ParseFloat(mantissa, 64)
+	out := loc.Format(m, len(frac))
+	if sci {
+		out += "e" + exp
+	}
+	return out, true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	if mag, neg, ok := toInteger(v); ok {
+		if neg {
+			return -float64(mag), true
+		}
+		return float64(mag), true
+	}
+	return 0, false
+}
+
+// toInteger returns the magnitude and sign of an integer of any Go integer
+// type. Unsigned values keep their full range.
+func toInteger(v interface{}) (mag uint64, neg bool, ok bool) {
+	var n int64
+	switch x := v.(type) {
+	case int:
+		n = int64(x)
+	case int8:
+		n = int64(x)
+	case int16:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint:
+		return uint64(x), false, true
+	case uint8:
+		return uint64(x), false, true
+	case uint16:
+		return uint64(x), false, true
+	case uint32:
+		return uint64(x), false, true
+	case uint64:
+		return x, false, true
+	default:
+		return 0, false, false
+	}
+	if n < 0 {
+		// Negate in unsigned arithmetic, which also holds math.MinInt64
+		return -uint64(n), true, true
+	}
+	return uint64(n), false, true
+}
@@ -0,0 +1,228 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/grandpaej/fmtpy/v2/color"
+)
+
+// confirmWords lists the accepted yes and no answers per language. The
+// first word of each list is shown in the [y/n] hint.
+var confirmWords = map[string][2][]string{
+	"en": {{"y", "yes", "true", "1"}, {"n", "no", "false", "0"}},
+	"fr": {{"o", "oui"}, {"n", "non"}},
+	"de": {{"j", "ja"}, {"n", "nein"}},
+	"es": {{"s", "sí", "si"}, {"n", "no"}},
+	"it": {{"s", "sì", "si"}, {"n", "no"}},
+	"pt": {{"s", "sim"}, {"n", "não", "nao"}},
+	"ja": {{"はい", "うん", "ええ"}, {"いいえ", "いや", "ううん"}},
+	"zh": {{"是", "是的", "好"}, {"否", "不", "不是"}},
+}
+
+// ConfirmOption configures Confirm
+type ConfirmOption func(*confirmConfig)
+
+type confirmConfig struct {
+	hasDefault bool
+	def        bool
+	yes        []string
+	no         []string
+}
+
+// DefaultYes makes an empty answer mean yes, shown as [Y/n]
+func DefaultYes() ConfirmOption {
+	return func(c *confirmConfig) {
+		c.hasDefault, c.def = true, true
+	}
+}
+
+// DefaultNo makes an empty answer mean no, shown as [y/N]
+func DefaultNo() ConfirmOption {
+	return func(c *confirmConfig) {
+		c.hasDefault, c.def = true, false
+	}
+}
+
+// ConfirmLanguage replaces the accepted answers with the built-in words for
+// a language code such as "fr" (oui/non) or "ja" (はい/いいえ). English
+// words stay accepted. Unknown codes leave the answers unchanged.
+func ConfirmLanguage(lang string) ConfirmOption {
+	return func(c *confirmConfig) {
+		words, ok := confirmWords[strings.ToLower(lang)]
+		if !ok {
+			return
+		}
+		en := confirmWords["en"]
+		c.yes = append(append([]string{}, words[0]...), en[0]...)
+		c.no = append(append([]string{}, words[1]...), en[1]...)
+	}
+}
+
+// ConfirmWords replaces the accepted answers. The first word of each list is
+// shown in the hint.
+//
+//	ok, err := input.Confirm("Continue?", input.ConfirmWords([]string{"da"}, []string{"nyet"}))
+func ConfirmWords(yes, no []string) ConfirmOption {
+	return func(c *confirmConfig) {
+		c.yes, c.no = yes, no
+	}
+}
+
+// Confirm asks a yes/no question and keeps asking until the answer is one of
+// the accepted words. With DefaultYes or DefaultNo an empty answer returns
+// the default; the default is also returned when input ends, otherwise the
+// error is io.EOF.
+//
+//	ok, err := input.Confirm("Delete file?", input.DefaultNo())   // Delete file? [y/N]
+//	ok, err := input.Confirm("続けますか?", input.ConfirmLanguage("ja"))
+func Confirm(prompt string, opts ...ConfirmOption) (bool, error) {
+	en := confirmWords["en"]
+	c := &confirmConfig{yes: en[0], no: en[1]}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if len(c.yes) == 0 || len(c.no) == 0 {
+		return false, errors.New("input: Confirm needs at least one yes and one no word")
+	}
+
+	hint := confirmHint(c)
+	console := DefaultConsole()
+	for {
+		answer, err := console.ReadLine(prompt + " " + hint + " ")
+		if err != nil {
+			if err == io.EOF && c.hasDefault {
+				console.Println()
+				return c.def, nil
+			}
+			return false, err
+		}
+
+		answer = strings.ToLower(answer)
+		switch {
+		case answer == "" && c.hasDefault:
+			return c.def, nil
+		case containsWord(c.yes, answer):
+			return true, nil
+		case containsWord(c.no, answer):
+			return false, nil
+		}
+		console.Println(color.Red(fmt.Sprintf("Please answer %s or %s", c.yes[0], c.no[0])))
+	}
+}
+
+// confirmHint builds the [y/n] hint, upper-casing the default answer. Words
+// without case, such as Japanese, mark the default in parentheses instead.
+func confirmHint(c *confirmConfig) string {
+	yes, no := c.yes[0], c.no[0]
+	if !c.hasDefault {
+		return "[" + yes + "/" + no + "]"
+	}
+
+	def := &no
+	if c.def {
+		def = &yes
+	}
+	if upper := strings.ToUpper(*def); upper != *def {
+		*def = upper
+		return "[" + yes + "/" + no + "]"
+	}
+	return "[" + yes + "/" + no + "] (" + *def + ")"
+}
+
+func containsWord(words []string, answer string) bool {
+	for _, w := range words {
+		if strings.ToLower(w) == answer {
+			return true
+		}
+	}
+	return false
+}
+
+// choiceOption is one entry of a Choice spec
+type choiceOption struct {
+	label  string
+	hotkey rune
+}
+
+// Choice asks the user to pick one of several options written as a spec
+// such as "[A]bort/[R]etry/[I]gnore" and returns the chosen label, e.g.
+// "Retry". The bracketed letter is the hotkey; without brackets the first
+// letter is used. Either the hotkey or the full label is accepted, ignoring
+// case, and the question is repeated until the answer is valid.
+//
+//	switch answer, _ := input.Choice("Disk not ready.", "[A]bort/[R]etry/[I]gnore"); answer {
+//	case "Retry":
+//	    ...
+//	}
+func Choice(prompt, spec string) (string, error) {
+	options, err := parseChoiceSpec(spec)
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, len(options))
+	for i, opt := range options {
+		keys[i] = string(unicode.ToUpper(opt.hotkey))
+	}
+
+	console := DefaultConsole()
+	for {
+		answer, err := console.ReadLine(prompt + " " + spec + ": ")
+		if err != nil {
+			return "", err
+		}
+
+		answer = strings.ToLower(answer)
+		for _, opt := range options {
+			if answer == string(opt.hotkey) || answer == strings.ToLower(opt.label) {
+				return opt.label, nil
+			}
+		}
+		console.Println(color.Red("Please choose one of " + strings.Join(keys, ", ")))
+	}
+}
+
+// parseChoiceSpec splits "[A]bort/[R]etry" into labels and lower-case hotkeys
+func parseChoiceSpec(spec string) ([]choiceOption, error) {
+	parts := strings.Split(spec, "/")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("input: choice spec %q needs at least two options separated by /", spec)
+	}
+
+	seen := make(map[rune]bool)
+	options := make([]choiceOption, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var hotkey rune
+		label := part
+		if open := strings.Index(part, "["); open >= 0 {
+			end := strings.Index(part[open:], "]")
+			if end < 0 {
+				return nil, fmt.Errorf("input: unclosed [ in choice %q", part)
+			}
+			key := []rune(part[open+1 : open+end])
+			if len(key) != 1 {
+				return nil, fmt.Errorf("input: hotkey in choice %q must be a single character", part)
+			}
+			hotkey = key[0]
+			label = part[:open] + string(key) + part[open+end+1:]
+		} else if label != "" {
+			hotkey = []rune(label)[0]
+		}
+		if label == "" {
+			return nil, fmt.Errorf("input: empty option in choice spec %q", spec)
+		}
+
+		hotkey = unicode.ToLower(hotkey)
+		if seen[hotkey] {
+			return nil, fmt.Errorf("input: hotkey %q used twice in choice spec %q", hotkey, spec)
+		}
+		seen[hotkey] = true
+		options = append(options, choiceOption{label: label, hotkey: hotkey})
+	}
+	return options, nil
+}
@@ -0,0 +1,482 @@
+package input
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/grandpaej/fmtpy/v2/internal/term"
+)
+
+// Console is where prompts are written and answers are read. Every prompt
+// in fmtpy, from fmtpy.Input to Select, goes through the default console.
+// Prompts, menus and validation messages are written to the console, never
+// to stdout, so a program's output can be piped without prompt text in it.
+//
+// A console can be given scripted answers so interactive programs run
+// unattended, for example in CI, and can record every prompt and answer to
+// a file that replays the session later.
+type Console struct {
+	mu        sync.Mutex
+	in        *bufio.Reader
+	dec       *decodeReader    // converts the input to UTF-8 below in
+	inFile    *os.File         // set when in reads a file, for terminal detection
+	intr      *interruptReader // set when in is a terminal
+	out       io.Writer
+	answers   []string          // scripted answers, used in order
+	answerMap map[string]string // scripted answers keyed by prompt
+	record    io.Writer
+	prompts   PromptMode
+	noInput   bool // fail instead of reading input
+	invalid   InvalidUTF8
+	skipLF    bool // the last line ended with "\r"; a "\n" right after it belongs to it
+}
+
+// ErrNonInteractive is returned when an answer is needed but the console is
+// in non-interactive mode and has no scripted answer for it
+var ErrNonInteractive = errors.New("input: an answer is required but input is non-interactive")
+
+// PromptMode controls when a console writes prompts
+type PromptMode int
+
+const (
+	// PromptAlways writes every prompt
+	PromptAlways PromptMode = iota
+	// PromptTerminal writes prompts only when answers are read from a
+	// terminal, so piped or scripted input runs silently
+	PromptTerminal
+	// PromptNever writes no prompts
+	PromptNever
+)
+
+// NewConsole returns a console reading answers from in and writing prompts
+// to out. Input is UTF-8 by default; see SetEncoding and SetInvalidUTF8.
+func NewConsole(in io.Reader, out io.Writer) *Console {
+	c := &Console{out: out}
+	src := in
+	if f, ok := in.(*os.File); ok {
+		c.inFile = f
+		if term.IsTerminal(f.Fd()) {
+			c.intr = &interruptReader{src: f}
+			src = c.intr
+		}
+	}
+	c.dec = &decodeReader{r: src}
+	c.in = bufio.NewReader(c.dec)
+	return c
+}
+
+var (
+	defaultMu      sync.Mutex
+	defaultConsole *Console
+)
+
+// NewTTYConsole returns a console that reads answers from and writes prompts
+// to the controlling terminal (/dev/tty), so a program can ask questions
+// while both stdin and stdout are redirected. It fails with
+// term.ErrNotTerminal when there is no terminal.
+func NewTTYConsole() (*Console, error) {
+	tty, err := term.OpenTTY()
+	if err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	return NewConsole(tty, tty), nil
+}
+
+// DefaultConsole returns the console used by the package-level prompts. It
+// reads stdin and writes prompts to stderr, leaving stdout to the program's
+// output. Environment variables change this:
+//
+//   - FMTPY_TTY=1 reads answers from and writes prompts to /dev/tty, even
+//     when stdin is a pipe
+//   - FMTPY_PROMPTS=terminal or never sets the PromptMode
+//   - FMTPY_NONINTERACTIVE=1 turns on non-interactive mode
+//   - FMTPY_ENCODING names the input encoding (see ParseEncoding)
+//   - FMTPY_ANSWERS names a file of scripted answers (see LoadAnswers)
+//   - FMTPY_RECORD names a file the session is appended to (see Record)
+func DefaultConsole() *Console {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultConsole == nil {
+		defaultConsole = newDefaultConsole()
+	}
+	return defaultConsole
+}
+
+// SetDefaultConsole replaces the console used by the package-level prompts
+// and returns the previous one
+func SetDefaultConsole(c *Console) *Console {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	prev := defaultConsole
+	if prev == nil {
+		prev = newDefaultConsole()
+	}
+	defaultConsole = c
+	return prev
+}
+
+func newDefaultConsole() *Console {
+	c := NewConsole(os.Stdin, os.Stderr)
+	if tty := os.Getenv("FMTPY_TTY"); tty != "" && tty != "0" {
+		if ttyConsole, err := NewTTYConsole(); err == nil {
+			c = ttyConsole
+		}
+	}
+	if name := os.Getenv("FMTPY_ENCODING"); name != "" {
+		if enc, err := ParseEncoding(name); err != nil {
+			fmt.Fprintln(os.Stderr, "fmtpy:", err)
+		} else {
+			c.dec.enc = enc
+		}
+	}
+	if v := os.Getenv("FMTPY_NONINTERACTIVE"); v != "" && v != "0" {
+		c.noInput = true
+	}
+	switch strings.ToLower(os.Getenv("FMTPY_PROMPTS")) {
+	case "terminal":
+		c.prompts = PromptTerminal
+	case "never":
+		c.prompts = PromptNever
+	}
+	if path := os.Getenv("FMTPY_ANSWERS"); path != "" {
+		if err := c.LoadAnswers(path); err != nil {
+			fmt.Fprintln(os.Stderr, "fmtpy:", err)
+		}
+	}
+	if path := os.Getenv("FMTPY_RECORD"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "fmtpy:", err)
+		} else {
+			c.Record(f)
+		}
+	}
+	return c
+}
+
+// SetPromptMode sets when prompts are written
+func (c *Console) SetPromptMode(m PromptMode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prompts = m
+}
+
+// SetNonInteractive turns non-interactive mode on or off. In this mode
+// prompts never wait for input: scripted answers are still used, and any
+// other prompt fails with ErrNonInteractive. Use it in CI or when a --yes
+// style flag promises the program will not ask questions.
+func (c *Console) SetNonInteractive(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.noInput = on
+}
+
+// showPrompts reports whether prompts should be written. The caller holds
+// c.mu.
+func (c *Console) showPrompts() bool {
+	switch c.prompts {
+	case PromptNever:
+		return false
+	case PromptTerminal:
+		return c.inputIsTerminal()
+	}
+	return true
+}
+
+// inputIsTerminal reports whether answers are typed at a terminal. Where
+// the terminal cannot be queried, such as on Windows, input from a
+// character device counts as typed, so PromptTerminal still shows prompts.
+func (c *Console) inputIsTerminal() bool {
+	if c.inFile == nil {
+		return false
+	}
+	if !term.Supported {
+		info, err := c.inFile.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	}
+	return term.IsTerminal(c.inFile.Fd())
+}
+
+// SetAnswers queues answers that are used, in order, for the next prompts
+// instead of reading input. Each scripted answer is echoed after its prompt.
+func (c *Console) SetAnswers(answers ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.answers = append([]string{}, answers...)
+}
+
+// SetAnswerMap scripts answers by prompt text, ignoring surrounding
+// whitespace in the prompt. Prompts in the map are answered every time they
+// are asked; other prompts use queued answers or read input.
+func (c *Console) SetAnswerMap(answers map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.answerMap = make(map[string]string, len(answers))
+	for prompt, answer := range answers {
+		c.answerMap[strings.TrimSpace(prompt)] = answer
+	}
+}
+
+// LoadAnswers scripts answers from a file. A JSON object maps prompt text
+// to answers and a JSON array lists answers in order. Any other file has one
+// answer per line; lines starting with # are comments and a leading
+// backslash escapes an answer that starts with # or \. Files written by
+// Record use this format, so a recorded session replays as is.
+func (c *Console) LoadAnswers(path string) error {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		data, err = c.decodeBytes(data)
+	}
+	if err != nil {
+		return fmt.Errorf("loading answers: %w", err)
+	}
+
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "{"):
+		var m map[string]string
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("loading answers from %s: %w", path, err)
+		}
+		c.SetAnswerMap(m)
+	case strings.HasPrefix(text, "["):
+		var list []string
+		if err := json.Unmarshal(data, &list); err != nil {
+			return fmt.Errorf("loading answers from %s: %w", path, err)
+		}
+		c.SetAnswers(list...)
+	default:
+		c.SetAnswers(parseAnswerLines(string(data))...)
+	}
+	return nil
+}
+
+// parseAnswerLines reads the line-based answers format
+func parseAnswerLines(s string) []string {
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if s == "" {
+		return nil
+	}
+	var answers []string
+	for _, line := range strings.Split(s, "\n") {
+		switch {
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "\\"):
+			line = line[1:]
+		}
+		answers = append(answers, line)
+	}
+	return answers
+}
+
+// Record writes every prompt and answer to w as they happen, in the format
+// LoadAnswers reads. A prompt that gets no answer, such as one at the end of
+// input, is recorded without one. Pass nil to stop recording.
+func (c *Console) Record(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record = w
+}
+
+// recordPrompt writes prompt to the recording as soon as it is asked, so
+// prompts that get no answer are recorded too. The caller holds c.mu.
+func (c *Console) recordPrompt(prompt string) {
+	if c.record == nil {
+		return
+	}
+	fmt.Fprintf(c.record, "# %s\n", strings.ReplaceAll(strings.TrimSpace(prompt), "\n", `\n`))
+}
+
+// recordAnswer writes the answer to the last recorded prompt. The caller
+// holds c.mu.
+func (c *Console) recordAnswer(answer string) {
+	if c.record == nil {
+		return
+	}
+	if strings.HasPrefix(answer, "#") || strings.HasPrefix(answer, "\\") {
+		answer = "\\" + answer
+	}
+	fmt.Fprintln(c.record, answer)
+}
+
+// scripted returns the scripted answer for prompt, if any
+func (c *Console) scripted(prompt string) (string, bool) {
+	if answer, ok := c.answerMap[strings.TrimSpace(prompt)]; ok {
+		return answer, true
+	}
+	if len(c.answers) > 0 {
+		answer := c.answers[0]
+		c.answers = c.answers[1:]
+		return answer, true
+	}
+	return "", false
+}
+
+// hasScript reports whether queued answers are pending or one of prompts
+// has an answer in the answer map
+func (c *Console) hasScript(prompts ...string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.answers) > 0 {
+		return true
+	}
+	for _, prompt := range prompts {
+		if _, ok := c.answerMap[strings.TrimSpace(prompt)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadLine writes prompt and returns the answer with surrounding whitespace
+// removed. It returns io.EOF only when the input is exhausted and nothing
+// was read, ErrNonInteractive in non-interactive mode and ErrInterrupted
+// when the user presses Ctrl-C (see OnInterrupt). Answers that were not
+// typed at a terminal, such as scripted or piped ones, are echoed after the
+// prompt so the session reads naturally.
+func (c *Console) ReadLine(prompt string) (string, error) {
+	for {
+		c.mu.Lock()
+		release := c.trapInterrupts()
+		answer, err := c.readLine(prompt, true)
+		release()
+		c.mu.Unlock()
+		if err == ErrInterrupted && resumeAfterInterrupt() {
+			continue
+		}
+		return answer, err
+	}
+}
+
+// trapInterrupts catches Ctrl-C for the rest of a prompt when input is a
+// terminal; see interruptReader.trap. The caller holds c.mu.
+func (c *Console) trapInterrupts() (release func()) {
+	if c.intr == nil {
+		return func() {}
+	}
+	return c.intr.trap()
+}
+
+// readLine implements ReadLine; without trim only the line ending is
+// removed from typed answers. The caller holds c.mu.
+func (c *Console) readLine(prompt string, trim bool) (string, error) {
+	answer, ok := c.scripted(prompt)
+	if !ok && c.noInput {
+		return "", ErrNonInteractive
+	}
+	show := c.showPrompts()
+	if show {
+		fmt.Fprint(c.out, prompt)
+	}
+	c.recordPrompt(prompt)
+	if ok {
+		if show {
+			fmt.Fprintln(c.out, answer)
+		}
+		c.recordAnswer(answer)
+		return answer, nil
+	}
+
+	text, err := c.readInputLine()
+	if err == ErrInterrupted {
+		// The terminal echoed ^C; end the line so output starts cleanly
+		if show {
+			fmt.Fprintln(c.out)
+		}
+		return "", err
+	}
+	if err != nil && text == "" {
+		return "", err
+	}
+	if answer, err = c.validUTF8(text); err != nil {
+		return "", err
+	}
+	if trim {
+		answer = strings.TrimSpace(answer)
+	}
+	if show && !c.inputIsTerminal() {
+		fmt.Fprintln(c.out, answer)
+	}
+	c.recordAnswer(answer)
+	return answer, nil
+}
+
+// readInputLine reads one line of input without its line ending, which
+// may be "\n", "\r\n" or a lone "\r". A line ending in "\r" returns at
+// once rather than waiting to see whether "\n" follows; the next call
+// skips that "\n" instead.
+func (c *Console) readInputLine() (string, error) {
+	var b strings.Builder
+	for {
+		ch, err := c.in.ReadByte()
+		if err != nil {
+			return b.String(), err
+		}
+		skipLF := c.skipLF
+		c.skipLF = false
+		switch {
+		case ch == '\n' && skipLF:
+			continue
+		case ch == '\n':
+			return b.String(), nil
+		case ch == '\r':
+			c.skipLF = true
+			return b.String(), nil
+		}
+		b.WriteByte(ch)
+	}
+}
+
+// Input writes prompt and returns the answer, or "" when input has ended.
+// Ctrl-C exits the program with InterruptExitCode unless an OnInterrupt
+// hook resumes the prompt.
+func (c *Console) Input(prompt string) string {
+	answer, err := c.ReadLine(prompt)
+	if err == ErrInterrupted {
+		os.Exit(InterruptExitCode)
+	}
+	return answer
+}
+
+// Printf writes formatted text to the console output. Like prompts, it is
+// suppressed when the PromptMode hides prompts.
+func (c *Console) Printf(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.showPrompts() {
+		fmt.Fprintf(c.out, format, args...)
+	}
+}
+
+// Println writes its operands and a newline to the console output. Like
+// prompts, it is suppressed when the PromptMode hides prompts.
+func (c *Console) Println(args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.showPrompts() {
+		fmt.Fprintln(c.out, args...)
+	}
+}
+
+// interactive reports whether the console reads from and writes to a
+// terminal and none of the fallback prompts has a scripted answer, so
+// raw-mode prompts can be used
+func (c *Console) interactive(fallbackPrompts ...string) bool {
+	out, ok := c.out.(*os.File)
+	return ok && c.inFile != nil && !c.hasScript(fallbackPrompts...) && !c.NonInteractive() &&
+		term.IsTerminal(c.inFile.Fd()) && term.IsTerminal(out.Fd())
+}
+
+// NonInteractive reports whether non-interactive mode is on
+func (c *Console) NonInteractive() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.noInput
+}
@@ -0,0 +1,38 @@
+package input
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHasScript(t *testing.T) {
+	c := NewConsole(strings.NewReader(""), io.Discard)
+	c.SetAnswerMap(map[string]string{"Continue? (y/n):": "y"})
+	if c.hasScript(numberPrompt(3)) {
+		t.Errorf("an answer for another prompt should not script the menu")
+	}
+	if !c.hasScript("Continue? (y/n): ") {
+		t.Errorf("the prompt in the answer map should be scripted")
+	}
+	c.SetAnswers("2")
+	if !c.hasScript(numberPrompt(3)) {
+		t.Errorf("queued answers should script any prompt")
+	}
+}
+
+func TestPrintlnWhileModeChanges(t *testing.T) {
+	c := NewConsole(strings.NewReader(""), io.Discard)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			c.SetPromptMode(PromptMode(i % 3))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		c.Println("line")
+		c.Printf("%d\n", i)
+	}
+	<-done
+}
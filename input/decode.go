@@ -0,0 +1,236 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the character encoding of console input
+type Encoding int
+
+const (
+	UTF8 Encoding = iota
+	UTF16LE
+	UTF16BE
+	Latin1      // ISO-8859-1
+	Windows1252 // Western European Windows code page
+)
+
+// encodingNames maps the names accepted by ParseEncoding
+var encodingNames = map[string]Encoding{
+	"utf8":        UTF8,
+	"utf16le":     UTF16LE,
+	"utf16be":     UTF16BE,
+	"latin1":      Latin1,
+	"iso88591":    Latin1,
+	"windows1252": Windows1252,
+	"cp1252":      Windows1252,
+}
+
+// ParseEncoding returns the encoding called name, such as "utf-16le",
+// "latin1" or "cp1252". Case, dashes and underscores are ignored.
+func ParseEncoding(name string) (Encoding, error) {
+	key := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+	if e, ok := encodingNames[key]; ok {
+		return e, nil
+	}
+	return UTF8, fmt.Errorf("input: unknown encoding %q", name)
+}
+
+// InvalidUTF8 selects what happens to answers containing bytes that are
+// not valid UTF-8
+type InvalidUTF8 int
+
+const (
+	// InvalidReplace replaces invalid bytes with U+FFFD
+	InvalidReplace InvalidUTF8 = iota
+	// InvalidError fails the prompt with ErrInvalidUTF8
+	InvalidError
+	// InvalidEscape writes each invalid byte as \xNN, like Python's
+	// backslashreplace
+	InvalidEscape
+)
+
+// ErrInvalidUTF8 is returned for answers with invalid UTF-8 when the
+// console uses InvalidError
+var ErrInvalidUTF8 = errors.New("input: answer is not valid UTF-8")
+
+// SetEncoding sets the encoding input is decoded from. A byte order mark at
+// the start of input takes precedence: UTF-8 and UTF-16 marks are removed
+// and select their encoding.
+func (c *Console) SetEncoding(e Encoding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dec.enc = e
+}
+
+// SetInvalidUTF8 sets how answers with invalid UTF-8 are handled. The
+// default is InvalidReplace.
+func (c *Console) SetInvalidUTF8(s InvalidUTF8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalid = s
+}
+
+// validUTF8 applies the console's InvalidUTF8 strategy to s
+func (c *Console) validUTF8(s string) (string, error) {
+	if utf8.ValidString(s) {
+		return s, nil
+	}
+	switch c.invalid {
+	case InvalidError:
+		return "", ErrInvalidUTF8
+	case InvalidEscape:
+		var b strings.Builder
+		for i := 0; i < len(s); {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				fmt.Fprintf(&b, `\x%02x`, s[i])
+			} else {
+				b.WriteString(s[i : i+size])
+			}
+			i += size
+		}
+		return b.String(), nil
+	}
+	return strings.ToValidUTF8(s, "\uFFFD"), nil
+}
+
+// windows1252 holds the characters of bytes 0x80-0x9F in Windows-1252.
+// The five unassigned bytes map to the C1 control of the same value.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// decodeReader converts input in enc to UTF-8, removing a leading byte
+// order mark. It returns what a single read of the source provides, so
+// terminal input is never held back waiting for more bytes.
+type decodeReader struct {
+	r       io.Reader
+	enc     Encoding
+	sniffed bool   // the byte order mark has been checked
+	raw     []byte // source bytes not decoded yet
+	out     []byte // decoded bytes not returned yet
+	buf     [4096]byte
+	err     error
+}
+
+func (d *decodeReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		n, err := d.r.Read(d.buf[:])
+		if err == ErrInterrupted {
+			return 0, err // not final: reading can continue after Ctrl-C
+		}
+		d.raw = append(d.raw, d.buf[:n]...)
+		d.err = err
+		if !d.sniffed {
+			if d.err == nil && isBOMPrefix(d.raw) {
+				continue
+			}
+			d.sniff()
+		}
+		d.decode(d.err != nil)
+	}
+	n := copy(p, d.out)
+	d.out = d.out[:copy(d.out, d.out[n:])]
+	return n, nil
+}
+
+// isBOMPrefix reports whether b could still become a byte order mark
+func isBOMPrefix(b []byte) bool {
+	for _, bom := range [][]byte{bomUTF8, bomUTF16LE, bomUTF16BE} {
+		if len(b) < len(bom) && bytes.HasPrefix(bom, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// sniff removes a byte order mark and switches to its encoding
+func (d *decodeReader) sniff() {
+	d.sniffed = true
+	switch {
+	case bytes.HasPrefix(d.raw, bomUTF8):
+		d.enc, d.raw = UTF8, d.raw[len(bomUTF8):]
+	case bytes.HasPrefix(d.raw, bomUTF16LE):
+		d.enc, d.raw = UTF16LE, d.raw[len(bomUTF16LE):]
+	case bytes.HasPrefix(d.raw, bomUTF16BE):
+		d.enc, d.raw = UTF16BE, d.raw[len(bomUTF16BE):]
+	}
+}
+
+// decode moves complete characters from raw to out. At the end of input an
+// incomplete UTF-16 sequence becomes U+FFFD.
+func (d *decodeReader) decode(final bool) {
+	switch d.enc {
+	case Latin1, Windows1252:
+		for _, b := range d.raw {
+			r := rune(b)
+			if d.enc == Windows1252 && b >= 0x80 && b <= 0x9F {
+				r = windows1252[b-0x80]
+			}
+			d.out = utf8.AppendRune(d.out, r)
+		}
+		d.raw = d.raw[:0]
+	case UTF16LE, UTF16BE:
+		i := 0
+		for ; i+2 <= len(d.raw); i += 2 {
+			u := d.unit(i)
+			if !utf16.IsSurrogate(rune(u)) {
+				d.out = utf8.AppendRune(d.out, rune(u))
+				continue
+			}
+			if i+4 > len(d.raw) && !final {
+				break
+			}
+			if i+4 <= len(d.raw) {
+				if r := utf16.DecodeRune(rune(u), rune(d.unit(i+2))); r != utf8.RuneError {
+					d.out = utf8.AppendRune(d.out, r)
+					i += 2
+					continue
+				}
+			}
+			d.out = utf8.AppendRune(d.out, utf8.RuneError)
+		}
+		d.raw = d.raw[:copy(d.raw, d.raw[i:])]
+		if final && len(d.raw) > 0 {
+			d.out = utf8.AppendRune(d.out, utf8.RuneError)
+			d.raw = d.raw[:0]
+		}
+	default:
+		d.out = append(d.out, d.raw...)
+		d.raw = d.raw[:0]
+	}
+}
+
+// unit returns the UTF-16 code unit at byte offset i of raw
+func (d *decodeReader) unit(i int) uint16 {
+	if d.enc == UTF16BE {
+		return uint16(d.raw[i])<<8 | uint16(d.raw[i+1])
+	}
+	return uint16(d.raw[i]) | uint16(d.raw[i+1])<<8
+}
+
+// decodeBytes decodes a whole file such as an answers file with the
+// console's encoding
+func (c *Console) decodeBytes(data []byte) ([]byte, error) {
+	c.mu.Lock()
+	enc := c.dec.enc
+	c.mu.Unlock()
+	return io.ReadAll(&decodeReader{r: bytes.NewReader(data), enc: enc})
+}
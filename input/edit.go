@@ -0,0 +1,153 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/grandpaej/fmtpy/v2/internal/term"
+)
+
+var (
+	// ErrEditUnchanged is returned by Edit when the text was saved unchanged
+	ErrEditUnchanged = errors.New("input: text was not changed")
+	// ErrEditEmpty is returned by Edit when the saved text is empty
+	ErrEditEmpty = errors.New("input: text is empty")
+)
+
+// EditOption configures Edit
+type EditOption func(*editConfig)
+
+type editConfig struct {
+	ext           string
+	command       string
+	stripComments bool
+}
+
+// EditExtension sets the extension of the temporary file, such as ".md",
+// so the editor picks the right syntax highlighting
+func EditExtension(ext string) EditOption {
+	return func(c *editConfig) {
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		c.ext = ext
+	}
+}
+
+// EditCommand runs command instead of $VISUAL or $EDITOR. It may include
+// arguments, as in "code --wait", and is run by the shell like git runs
+// editors, so paths with spaces can be quoted.
+func EditCommand(command string) EditOption {
+	return func(c *editConfig) {
+		c.command = command
+	}
+}
+
+// EditStripComments removes lines starting with # from the result, so the
+// initial text can carry instructions like a git commit message template
+func EditStripComments() EditOption {
+	return func(c *editConfig) {
+		c.stripComments = true
+	}
+}
+
+// Edit opens initial in the user's editor and returns the saved text with
+// trailing whitespace removed. The editor is $VISUAL, then $EDITOR, then vi.
+// Like git, Edit runs it with sh -c, so it may hold arguments and quotes;
+// on Windows it is split at spaces and run directly. The editor runs
+// attached to the terminal even when stdin or stdout are redirected. Edit
+// returns the text along with ErrEditUnchanged when it was saved as it was
+// given, and with ErrEditEmpty when nothing is left.
+//
+//	msg, err := input.Edit("\n# Describe your change. Lines starting with # are removed.\n",
+//	    input.EditExtension(".txt"), input.EditStripComments())
+//	if errors.Is(err, input.ErrEditEmpty) {
+//	    fmt.Println("Aborting: empty message")
+//	}
+func Edit(initial string, opts ...EditOption) (string, error) {
+	cfg := &editConfig{ext: ".txt"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	f, err := os.CreateTemp("", "fmtpy-edit-*"+cfg.ext)
+	if err != nil {
+		return "", fmt.Errorf("input: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	_, err = f.WriteString(initial)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("input: %w", err)
+	}
+
+	if err := runEditor(cfg.editor(), path); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("input: %w", err)
+	}
+	text := cfg.clean(string(data))
+	switch {
+	case strings.TrimSpace(text) == "":
+		return "", ErrEditEmpty
+	case text == cfg.clean(initial):
+		return text, ErrEditUnchanged
+	}
+	return text, nil
+}
+
+// editor returns the editor command line
+func (cfg *editConfig) editor() string {
+	for _, command := range []string{cfg.command, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(command) != "" {
+			return command
+		}
+	}
+	return "vi"
+}
+
+// clean removes comment lines if requested and trailing whitespace
+func (cfg *editConfig) clean(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if cfg.stripComments {
+		lines := strings.Split(s, "\n")
+		kept := lines[:0]
+		for _, line := range lines {
+			if !strings.HasPrefix(line, "#") {
+				kept = append(kept, line)
+			}
+		}
+		s = strings.Join(kept, "\n")
+	}
+	return strings.TrimRight(s, " \t\n")
+}
+
+// runEditor runs the editor on path and waits for it to exit. The editor
+// gets the controlling terminal when there is one, so it works in a pipeline.
+func runEditor(editor, path string) error {
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(editor)
+		cmd = exec.Command(fields[0], append(fields[1:], path)...)
+	}
+	if tty, err := term.OpenTTY(); err == nil {
+		defer tty.Close()
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	} else {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("input: running editor %s: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,523 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/grandpaej/fmtpy/v2/color"
+)
+
+// Scoring weights for fuzzy matching. Matches at word boundaries and runs
+// of consecutive characters rank higher; gaps between matches cost points.
+const (
+	scoreMatch        = 16
+	bonusBoundary     = 8
+	bonusConsecutive  = 6
+	penaltyGapStart   = 3
+	penaltyGapExtend  = 1
+	fuzzyFallbackRows = 20
+)
+
+// FuzzyOption configures FuzzyFind
+type FuzzyOption func(*fuzzyFinder)
+
+// FuzzyMulti lets the user pick several items with Tab before pressing Enter
+func FuzzyMulti() FuzzyOption {
+	return func(f *fuzzyFinder) {
+		f.multi = true
+	}
+}
+
+// FuzzyPreview shows the text returned by fn for the highlighted item below the list
+func FuzzyPreview(fn func(item string) string) FuzzyOption {
+	return func(f *fuzzyFinder) {
+		f.preview = fn
+	}
+}
+
+// FuzzyFind lets the user search items by typing a fuzzy pattern, like fzf,
+// and returns the indexes of the chosen items. Items are ranked by how well
+// the pattern matches as a subsequence, with matched characters highlighted.
+// Matching ignores case unless the pattern contains an uppercase letter.
+//
+//	picked, err := input.FuzzyFind("Branch:", branches)
+//	picked, err := input.FuzzyFind("Files:", files, input.FuzzyMulti(), input.FuzzyPreview(readHead))
+//
+// Without a terminal the user enters a search line and then picks from a
+// numbered list of the best matches.
+func FuzzyFind(prompt string, items []string, opts ...FuzzyOption) ([]int, error) {
+	if len(items) == 0 {
+		return nil, ErrNoOptions
+	}
+
+	f := newFuzzyFinder(prompt, items)
+	for _, opt := range opts {
+		opt(f)
+	}
+	c := DefaultConsole()
+	if !c.interactive(f.searchPrompt()) {
+		return f.fallback(c)
+	}
+
+	reserved := 3
+	if f.preview != nil {
+		reserved = f.pageSize + 4
+	}
+	f.width, f.pageSize = c.pageLayout(f.pageSize, reserved)
+	if err := runInteractive(c, f); err != nil {
+		return nil, err
+	}
+	return f.chosen(), nil
+}
+
+// FuzzyMatch reports whether pattern matches text as a subsequence and
+// returns a score (higher is better) with the rune positions of the matched
+// characters. Matching ignores case unless pattern contains an uppercase letter.
+func FuzzyMatch(pattern, text string) (score int, positions []int, ok bool) {
+	pat := []rune(pattern)
+	fold := !hasUpper(pat)
+	if fold {
+		pat = foldRunes(pat)
+	}
+	runes := []rune(text)
+	haystack := runes
+	if fold {
+		haystack = foldRunes(runes)
+	}
+	return fuzzyScore(pat, haystack, runes, true)
+}
+
+// fuzzyScore scores pat against haystack, the possibly case-folded form of
+// text. The match is first located with a forward scan, then tightened with
+// a backward scan so "abc" in "a_abc" matches the later, denser run.
+// Positions are only collected when wantPositions is set.
+func fuzzyScore(pat, haystack, text []rune, wantPositions bool) (int, []int, bool) {
+	if len(pat) == 0 {
+		return 0, nil, true
+	}
+
+	pi, end := 0, -1
+	for ti, r := range haystack {
+		if r == pat[pi] {
+			pi++
+			if pi == len(pat) {
+				end = ti
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	pi, start := len(pat)-1, 0
+	for ti := end; ti >= 0; ti-- {
+		if haystack[ti] == pat[pi] {
+			pi--
+			if pi < 0 {
+				start = ti
+				break
+			}
+		}
+	}
+
+	var positions []int
+	if wantPositions {
+		positions = make([]int, 0, len(pat))
+	}
+	score, gap, prev := 0, 0, -2
+	pi = 0
+	for ti := start; ti <= end && pi < len(pat); ti++ {
+		if haystack[ti] != pat[pi] {
+			if gap == 0 {
+				score -= penaltyGapStart
+			} else {
+				score -= penaltyGapExtend
+			}
+			gap++
+			continue
+		}
+
+		score += scoreMatch
+		if ti == 0 || isBoundary(text[ti-1], text[ti]) {
+			score += bonusBoundary
+		}
+		if prev == ti-1 {
+			score += bonusConsecutive
+		}
+		if wantPositions {
+			positions = append(positions, ti)
+		}
+		prev, gap = ti, 0
+		pi++
+	}
+	return score, positions, true
+}
+
+// isBoundary reports whether cur starts a new word after prev
+func isBoundary(prev, cur rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+func hasUpper(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func foldRunes(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = unicode.ToLower(r)
+	}
+	return folded
+}
+
+// fuzzyResult is an item that matches the current pattern
+type fuzzyResult struct {
+	index int
+	score int
+}
+
+// fuzzyFinder holds the state of a FuzzyFind prompt
+type fuzzyFinder struct {
+	prompt   string
+	items    []string
+	runes    [][]rune
+	folded   [][]rune
+	multi    bool
+	preview  func(string) string
+	query    []rune
+	scored   string // query the current matches were computed for
+	matches  []fuzzyResult
+	selected map[int]bool
+	cursor   int
+	offset   int
+	pageSize int
+	width    int
+}
+
+func newFuzzyFinder(prompt string, items []string) *fuzzyFinder {
+	f := &fuzzyFinder{
+		prompt:   prompt,
+		items:    items,
+		runes:    make([][]rune, len(items)),
+		folded:   make([][]rune, len(items)),
+		selected: make(map[int]bool),
+		pageSize: defaultPageSize,
+	}
+	for i, item := range items {
+		f.runes[i] = []rune(item)
+		f.folded[i] = foldRunes(f.runes[i])
+	}
+	f.rank()
+	return f
+}
+
+// rank recomputes matches for the current query. When the query only grew
+// since the last ranking, just the previous matches are rescored, since an
+// item that failed to match a pattern cannot match a longer one.
+func (f *fuzzyFinder) rank() {
+	query := string(f.query)
+	if len(f.query) == 0 {
+		f.matches = f.matches[:0]
+		for i := range f.items {
+			f.matches = append(f.matches, fuzzyResult{index: i})
+		}
+		f.scored = query
+		f.cursor, f.offset = 0, 0
+		return
+	}
+
+	pat := f.query
+	fold := !hasUpper(pat)
+	if fold {
+		pat = foldRunes(pat)
+	}
+
+	var candidates []fuzzyResult
+	if f.scored != "" && strings.HasPrefix(query, f.scored) && hasUpper([]rune(f.scored)) == !fold {
+		candidates = f.matches
+	} else {
+		candidates = make([]fuzzyResult, len(f.items))
+		for i := range f.items {
+			candidates[i] = fuzzyResult{index: i}
+		}
+	}
+
+	matches := candidates[:0]
+	for _, c := range candidates {
+		haystack := f.runes[c.index]
+		if fold {
+			haystack = f.folded[c.index]
+		}
+		if score, _, ok := fuzzyScore(pat, haystack, f.runes[c.index], false); ok {
+			matches = append(matches, fuzzyResult{index: c.index, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		ma, mb := matches[a], matches[b]
+		if ma.score != mb.score {
+			return ma.score > mb.score
+		}
+		if la, lb := len(f.runes[ma.index]), len(f.runes[mb.index]); la != lb {
+			return la < lb
+		}
+		return ma.index < mb.index
+	})
+
+	f.matches = matches
+	f.scored = query
+	f.cursor, f.offset = 0, 0
+}
+
+// handle applies a key press and reports whether the prompt is finished
+func (f *fuzzyFinder) handle(k keyEvent) (bool, error) {
+	switch k.kind {
+	case keyUp:
+		f.move(-1)
+	case keyDown:
+		f.move(1)
+	case keyPageUp:
+		f.move(-f.pageSize)
+	case keyPageDown:
+		f.move(f.pageSize)
+	case keyTab:
+		if f.multi && len(f.matches) > 0 {
+			i := f.matches[f.cursor].index
+			if f.selected[i] {
+				delete(f.selected, i)
+			} else {
+				f.selected[i] = true
+			}
+		}
+		f.move(1)
+	case keyRune, keySpace:
+		f.query = append(f.query, k.r)
+		f.rank()
+	case keyBackspace:
+		if len(f.query) > 0 {
+			f.query = f.query[:len(f.query)-1]
+			f.rank()
+		}
+	case keyCtrlU:
+		f.query = f.query[:0]
+		f.rank()
+	case keyEnter:
+		if len(f.matches) > 0 || len(f.selected) > 0 {
+			return true, nil
+		}
+	case keyCtrlC:
+		return false, ErrInterrupted
+	case keyEscape, keyCtrlD:
+		return false, ErrAborted
+	}
+	return false, nil
+}
+
+// move shifts the highlight by delta rows, clamped to the match list
+func (f *fuzzyFinder) move(delta int) {
+	if len(f.matches) == 0 {
+		return
+	}
+	f.cursor += delta
+	if f.cursor < 0 {
+		f.cursor = 0
+	} else if f.cursor >= len(f.matches) {
+		f.cursor = len(f.matches) - 1
+	}
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	} else if f.cursor >= f.offset+f.pageSize {
+		f.offset = f.cursor - f.pageSize + 1
+	}
+}
+
+// render returns the query line, the visible matches and the preview
+func (f *fuzzyFinder) render() []string {
+	count := color.New(color.Faint).Sprint(fmt.Sprintf(" %d/%d", len(f.matches), len(f.items)))
+	if f.multi && len(f.selected) > 0 {
+		count += color.New(color.Faint).Sprint(fmt.Sprintf(" (%d selected)", len(f.selected)))
+	}
+	lines := []string{color.BoldCyan("? ") + f.prompt + " " + color.Yellow(string(f.query)) + count}
+
+	end := f.offset + f.pageSize
+	if end > len(f.matches) {
+		end = len(f.matches)
+	}
+	for row := f.offset; row < end; row++ {
+		lines = append(lines, f.renderItem(f.matches[row].index, row == f.cursor))
+	}
+	if len(f.matches) == 0 {
+		lines = append(lines, color.New(color.Faint).Sprint("  no matches"))
+	}
+
+	if f.preview != nil && len(f.matches) > 0 {
+		rule := strings.Repeat("─", 20)
+		if f.width > 4 {
+			rule = strings.Repeat("─", f.width-2)
+		}
+		lines = append(lines, color.New(color.Faint).Sprint(rule))
+		text := f.preview(f.items[f.matches[f.cursor].index])
+		for i, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			if i == f.pageSize {
+				break
+			}
+			lines = append(lines, clip(line, f.width-1))
+		}
+	}
+	return lines
+}
+
+// renderItem draws one match with its matched characters highlighted
+func (f *fuzzyFinder) renderItem(i int, current bool) string {
+	runes := f.runes[i]
+	if limit := f.width - 8; limit > 1 && len(runes) > limit {
+		runes = append(runes[:limit-1:limit-1], '…')
+	}
+
+	var positions []int
+	if len(f.query) > 0 {
+		_, positions, _ = FuzzyMatch(string(f.query), f.items[i])
+	}
+	highlight := color.New(color.Bold, color.FgYellow)
+	base := color.New(color.Bold, color.FgCyan)
+
+	var b strings.Builder
+	if current {
+		b.WriteString(color.Cyan("> "))
+	} else {
+		b.WriteString("  ")
+	}
+	if f.multi {
+		if f.selected[i] {
+			b.WriteString(color.Green("[x]") + " ")
+		} else {
+			b.WriteString("[ ] ")
+		}
+	}
+
+	hit := make([]bool, len(runes))
+	for _, pos := range positions {
+		if pos < len(hit) {
+			hit[pos] = true
+		}
+	}
+
+	// Emit runs of matched and unmatched characters
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && hit[end] == hit[start] {
+			end++
+		}
+		switch segment := string(runes[start:end]); {
+		case hit[start]:
+			b.WriteString(highlight.Sprint(segment))
+		case current:
+			b.WriteString(base.Sprint(segment))
+		default:
+			b.WriteString(segment)
+		}
+		start = end
+	}
+	return b.String()
+}
+
+// title returns the prompt text
+func (f *fuzzyFinder) title() string {
+	return f.prompt
+}
+
+// chosen returns the selected item indexes in their original order, or the
+// highlighted item when nothing was toggled
+func (f *fuzzyFinder) chosen() []int {
+	if len(f.selected) == 0 {
+		if len(f.matches) == 0 {
+			return []int{}
+		}
+		return []int{f.matches[f.cursor].index}
+	}
+	chosen := make([]int, 0, len(f.selected))
+	for i := range f.items {
+		if f.selected[i] {
+			chosen = append(chosen, i)
+		}
+	}
+	return chosen
+}
+
+// summary describes the final choice for the line left behind on screen
+func (f *fuzzyFinder) summary() string {
+	chosen := f.chosen()
+	if len(chosen) > 3 {
+		return fmt.Sprintf("%d items", len(chosen))
+	}
+	names := make([]string, len(chosen))
+	for i, c := range chosen {
+		names[i] = f.items[c]
+	}
+	return strings.Join(names, ", ")
+}
+
+// searchPrompt returns the prompt for the search pattern of the fallback
+func (f *fuzzyFinder) searchPrompt() string {
+	return f.prompt + " search: "
+}
+
+// fallback reads a search pattern and a choice from a numbered list of the
+// best matches when no terminal is attached
+func (f *fuzzyFinder) fallback(c *Console) ([]int, error) {
+	for {
+		line, err := c.ReadLine(f.searchPrompt())
+		if err != nil {
+			return nil, err
+		}
+		f.query = []rune(line)
+		f.rank()
+		if len(f.matches) == 0 {
+			c.Println(color.Red("No matches, try again"))
+			continue
+		}
+
+		shown := f.matches
+		if len(shown) > fuzzyFallbackRows {
+			shown = shown[:fuzzyFallbackRows]
+		}
+		width := len(strconv.Itoa(len(shown)))
+		for n, m := range shown {
+			c.Printf("  %*d) %s\n", width, n+1, f.items[m.index])
+		}
+
+		pick := fmt.Sprintf("Enter a number (1-%d, empty to search again): ", len(shown))
+		if f.multi {
+			pick = "Enter numbers separated by commas (empty to search again): "
+		}
+		line, err = c.ReadLine(pick)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+		picked, err := parseSelection(line, len(shown))
+		if err != nil || (!f.multi && len(picked) != 1) {
+			c.Println(color.Red(fmt.Sprintf("Please enter a number between 1 and %d", len(shown))))
+			continue
+		}
+		chosen := make([]int, len(picked))
+		for n, p := range picked {
+			chosen[n] = shown[p].index
+		}
+		sort.Ints(chosen)
+		return chosen, nil
+	}
+}
@@ -0,0 +1,54 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRankNarrows(t *testing.T) {
+	items := []string{"main.go", "model.go", "readme.md", "Makefile", "go.mod"}
+	names := func(f *fuzzyFinder) []string {
+		got := []string{}
+		for _, m := range f.matches {
+			got = append(got, f.items[m.index])
+		}
+		return got
+	}
+
+	// Typing a query letter by letter rescores only the previous matches,
+	// which must give the same result as ranking from scratch
+	f := newFuzzyFinder("Files:", items)
+	prevQuery, prev := "", len(items)
+	for _, tt := range []struct {
+		query, want string
+	}{
+		{"m", "[go.mod main.go model.go Makefile readme.md]"},
+		{"mo", "[go.mod model.go main.go]"},
+		{"mod", "[go.mod model.go]"},
+		{"mode", "[model.go]"},
+		{"modex", "[]"},
+		{"m", "[go.mod main.go model.go Makefile readme.md]"}, // deleting widens again
+		{"M", "[Makefile]"},                                   // an upper-case letter matches case
+		{"Ma", "[Makefile]"},
+		{"ma", "[main.go Makefile]"},
+	} {
+		f.query = []rune(tt.query)
+		f.rank()
+		got := names(f)
+		if fmt.Sprint(got) != tt.want {
+			t.Errorf("rank(%q) = %v; want %s", tt.query, got, tt.want)
+		}
+
+		fresh := newFuzzyFinder("Files:", items)
+		fresh.query = []rune(tt.query)
+		fresh.rank()
+		if fmt.Sprint(names(fresh)) != fmt.Sprint(got) {
+			t.Errorf("rank(%q) after narrowing = %v; from scratch %v", tt.query, got, names(fresh))
+		}
+		if strings.HasPrefix(tt.query, prevQuery) && len(got) > prev {
+			t.Errorf("rank(%q) found %d matches, more than the %d for %q", tt.query, len(got), prev, prevQuery)
+		}
+		prevQuery, prev = tt.query, len(got)
+	}
+}
@@ -2,22 +2,20 @@
 package input
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"unicode"
-)
 
-var reader = bufio.NewReader(os.Stdin)
+	"github.com/grandpaej/fmtpy/v2/color"
+)
 
 // Input prompts the user for input and returns the entered string
 // If the prompt ends with \n, it will be printed on a new line
+// Answers come from the default console, so they can be scripted with
+// FMTPY_ANSWERS (see DefaultConsole).
 func Input(prompt string) string {
-	fmt.Print(prompt)
-	text, _ := reader.ReadString('\n')
-	return strings.TrimSpace(text)
+	return DefaultConsole().Input(prompt)
 }
 
 // InputInt prompts for input and converts to integer
@@ -30,7 +28,8 @@ func InputFloat(prompt string) float64 {
 	return ToFloat(Input(prompt))
 }
 
-// Ask prompts for yes/no input and returns boolean
+// Ask prompts for yes/no input and returns boolean. Any answer other than
+// y/yes/true/1 counts as no; use Confirm to re-ask on typos or set a default.
 func Ask(prompt string) bool {
 	response := Lower(Trim(Input(prompt + " (y/n): ")))
 	return response == "y" || response == "yes" || response == "true" || response == "1"
@@ -151,20 +150,20 @@ func IsEmpty(s string) bool {
 	return strings.TrimSpace(s) == ""
 }
 
-// ToInt converts a string to integer, returns 0 if conversion fails
+// ToInt converts a string to integer, returns 0 if conversion fails.
+// Besides plain decimals it accepts Python literals such as "1_000", "0x1F",
+// "0b101" and "+5", and whole-valued decimals such as "3.0".
 func ToInt(s string) int {
-	if i, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
-		return i
-	}
-	return 0
+	i, _ := ToIntE(s)
+	return i
 }
 
-// ToFloat converts a string to float64, returns 0.0 if conversion fails
+// ToFloat converts a string to float64, returns 0.0 if conversion fails.
+// It accepts the spellings Python's float() does, including "1_000.5",
+// "inf" and "nan".
 func ToFloat(s string) float64 {
-	if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
-		return f
-	}
-	return 0.0
+	f, _ := ToFloatE(s)
+	return f
 }
 
 // ToString converts any value to string
@@ -172,39 +171,23 @@ func ToString(v interface{}) string {
 	return fmt.Sprint(v)
 }
 
-// Center centers a string within a given width, padding with spaces
+// Center centers a string within a given width, padding with spaces. The
+// width is measured in terminal columns, so colored and wide text is
+// centered by what is visible.
 func Center(s string, width int) string {
-	if len(s) >= width {
-		return s
-	}
-	padding := width - len(s)
-	leftPad := padding / 2
-	rightPad := padding - leftPad
-	return strings.Repeat(" ", leftPad) + s + strings.Repeat(" ", rightPad)
+	return color.Center(s, width)
 }
 
-// LeftPad pads a string on the left to reach the specified width
+// LeftPad pads a string on the left to reach the specified width in
+// terminal columns
 func LeftPad(s string, width int, padChar string) string {
-	if len(padChar) == 0 {
-		padChar = " "
-	}
-	if len(s) >= width {
-		return s
-	}
-	padding := width - len(s)
-	return strings.Repeat(padChar, padding) + s
+	return color.LeftPad(s, width, padChar)
 }
 
-// RightPad pads a string on the right to reach the specified width
+// RightPad pads a string on the right to reach the specified width in
+// terminal columns
 func RightPad(s string, width int, padChar string) string {
-	if len(padChar) == 0 {
-		padChar = " "
-	}
-	if len(s) >= width {
-		return s
-	}
-	padding := width - len(s)
-	return s + strings.Repeat(padChar, padding)
+	return color.RightPad(s, width, padChar)
 }
 
 // Additional utility functions
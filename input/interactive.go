@@ -0,0 +1,138 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/grandpaej/fmtpy/v2/color"
+	"github.com/grandpaej/fmtpy/v2/internal/term"
+)
+
+// interactiveModel is implemented by prompts that run in raw mode and
+// redraw themselves after every key press
+type interactiveModel interface {
+	// title is the prompt text repeated on the summary line
+	title() string
+	// render returns the lines to draw for the current state
+	render() []string
+	// handle applies a key press and reports whether the prompt is finished
+	handle(k keyEvent) (bool, error)
+	// summary describes the final answer
+	summary() string
+}
+
+// runInteractive puts the terminal in raw mode and feeds keys to m until it
+// is finished, leaving a one-line summary of the answer on screen. On Ctrl-C
+// the terminal is restored before the OnInterrupt hook runs; if the hook
+// resumes, the prompt is drawn again with its state intact.
+func runInteractive(c *Console, m interactiveModel) error {
+	for {
+		err := runRaw(c, m)
+		if err == ErrInterrupted && resumeAfterInterrupt() {
+			continue
+		}
+		return err
+	}
+}
+
+// runRaw runs m once in raw mode. Keys that are already buffered are
+// applied before redrawing so fast typing and pastes do not trigger a
+// render per character.
+func runRaw(c *Console, m interactiveModel) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	defer c.trapInterrupts()()
+
+	fd := c.inFile.Fd()
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	out := bufio.NewWriter(c.out)
+	fmt.Fprint(out, "\033[?25l")
+	defer func() {
+		fmt.Fprint(out, "\033[?25h")
+		out.Flush()
+	}()
+
+	drawn := 0
+	for {
+		drawn = redraw(out, drawn, m.render())
+		out.Flush()
+
+		for {
+			k, err := readKey(c.in)
+			if err == ErrInterrupted {
+				redraw(out, drawn, nil)
+				fmt.Fprintln(out)
+				return err
+			}
+			if err != nil {
+				redraw(out, drawn, nil)
+				return err
+			}
+			done, err := m.handle(k)
+			if err != nil {
+				redraw(out, drawn, nil)
+				fmt.Fprintln(out)
+				return err
+			}
+			if done {
+				redraw(out, drawn, nil)
+				fmt.Fprintf(out, "%s %s\n", m.title(), color.Cyan(m.summary()))
+				return nil
+			}
+			if c.in.Buffered() == 0 {
+				break
+			}
+		}
+	}
+}
+
+// redraw erases the previous drawn lines and writes lines in their place,
+// returning the number of lines now on screen
+func redraw(out *bufio.Writer, drawn int, lines []string) int {
+	if drawn > 1 {
+		fmt.Fprintf(out, "\033[%dA", drawn-1)
+	}
+	if drawn > 0 {
+		fmt.Fprint(out, "\r\033[J")
+	}
+	for i, line := range lines {
+		if i > 0 {
+			fmt.Fprint(out, "\n")
+		}
+		fmt.Fprint(out, line)
+	}
+	return len(lines)
+}
+
+// pageLayout returns the terminal width and the number of rows available
+// for a list, leaving reserved lines for headers and footers
+func (c *Console) pageLayout(pageSize, reserved int) (width, rows int) {
+	out, ok := c.out.(*os.File)
+	if !ok {
+		return 0, pageSize
+	}
+	w, h, err := term.Size(out.Fd())
+	if err != nil {
+		return 0, pageSize
+	}
+	if h-reserved < pageSize && h > reserved {
+		pageSize = h - reserved
+	}
+	return w, pageSize
+}
+
+// clip shortens s to at most width columns, marking the cut with an
+// ellipsis. A width of 1 or less leaves s unchanged.
+func clip(s string, width int) string {
+	if width <= 1 {
+		return s
+	}
+	return color.Truncate(s, width, "…")
+}
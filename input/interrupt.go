@@ -0,0 +1,161 @@
+package input
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// interruptError is the type of ErrInterrupted. It matches ErrAborted too,
+// so code written for cancelled menus also handles Ctrl-C.
+type interruptError struct{}
+
+func (interruptError) Error() string { return "input: interrupted" }
+
+func (interruptError) Is(target error) bool { return target == ErrAborted }
+
+// ErrInterrupted is returned when the user presses Ctrl-C while a prompt
+// is waiting. The terminal is restored and the line ended before it is
+// returned. errors.Is(err, ErrAborted) also reports true for it.
+var ErrInterrupted error = interruptError{}
+
+// InterruptExitCode is the exit status used by Input and the other prompts
+// without an error result when the user presses Ctrl-C, as a shell would
+// report for SIGINT
+const InterruptExitCode = 130
+
+var (
+	interruptMu   sync.Mutex
+	interruptHook func() bool
+	inHook        bool
+)
+
+// OnInterrupt registers fn to run when the user presses Ctrl-C during a
+// prompt. If fn returns true the prompt keeps waiting for an answer;
+// otherwise the prompt returns ErrInterrupted, or exits the program for
+// prompts without an error result. Pass nil to remove the hook. A Ctrl-C
+// during a prompt asked by fn itself is not passed to fn again.
+//
+//	input.OnInterrupt(func() bool {
+//	    quit, _ := input.Confirm("Are you sure you want to quit?", input.DefaultNo())
+//	    return !quit
+//	})
+func OnInterrupt(fn func() bool) {
+	interruptMu.Lock()
+	defer interruptMu.Unlock()
+	interruptHook = fn
+}
+
+// resumeAfterInterrupt runs the OnInterrupt hook and reports whether the
+// interrupted prompt should continue
+func resumeAfterInterrupt() bool {
+	interruptMu.Lock()
+	fn := interruptHook
+	if fn == nil || inHook {
+		interruptMu.Unlock()
+		return false
+	}
+	inHook = true
+	interruptMu.Unlock()
+
+	defer func() {
+		interruptMu.Lock()
+		inHook = false
+		interruptMu.Unlock()
+	}()
+	return fn()
+}
+
+// interruptReader reads a terminal so that a waiting read can be abandoned
+// when SIGINT arrives. A helper goroutine performs one read of the source
+// at a time, and only when data is requested, so no input is taken that the
+// console did not ask for. A read abandoned by Ctrl-C stays outstanding and
+// its data is returned by the next Read.
+type interruptReader struct {
+	src     io.Reader
+	request chan struct{}
+	result  chan readResult
+	waiting bool           // a read of src is outstanding
+	left    []byte         // data received but not returned yet
+	trapped chan os.Signal // SIGINT during the current prompt, see trap
+	err     error
+}
+
+// trap catches SIGINT from now until release is called, for the whole of a
+// prompt. A Ctrl-C that arrives between reads, such as while a menu is
+// redrawn, is then reported by the next Read instead of killing the
+// process. The console lock must be held.
+func (r *interruptReader) trap() (release func()) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	r.trapped = sig
+	return func() {
+		signal.Stop(sig)
+		r.trapped = nil
+	}
+}
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+func (r *interruptReader) Read(p []byte) (int, error) {
+	if len(r.left) > 0 {
+		n := copy(p, r.left)
+		r.left = r.left[n:]
+		return n, nil
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	if r.request == nil {
+		r.request = make(chan struct{})
+		r.result = make(chan readResult, 1)
+		go r.pump()
+	}
+	if !r.waiting {
+		r.request <- struct{}{}
+		r.waiting = true
+	}
+
+	sig := r.trapped
+	if sig == nil {
+		sig = make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		defer signal.Stop(sig)
+	}
+	select {
+	case <-sig:
+		return 0, ErrInterrupted
+	default:
+	}
+
+	select {
+	case res := <-r.result:
+		r.waiting = false
+		r.err = res.err
+		n := copy(p, res.data)
+		r.left = res.data[n:]
+		if len(r.left) > 0 {
+			return n, nil
+		}
+		return n, res.err
+	case <-sig:
+		return 0, ErrInterrupted
+	}
+}
+
+// pump reads src each time a read is requested
+func (r *interruptReader) pump() {
+	for range r.request {
+		buf := make([]byte, 4096)
+		n, err := r.src.Read(buf)
+		r.result <- readResult{data: buf[:n], err: err}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTTY is terminal input typed line by line. The first interrupts reads
+// press Ctrl-C, by sending SIGINT to the test process, before waiting for
+// a line.
+type fakeTTY struct {
+	t          *testing.T
+	lines      chan string
+	interrupts int
+}
+
+func newFakeTTY(t *testing.T, interrupts int) *fakeTTY {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGINT cannot be sent on Windows")
+	}
+	return &fakeTTY{t: t, lines: make(chan string, 4), interrupts: interrupts}
+}
+
+func (f *fakeTTY) Read(p []byte) (int, error) {
+	if f.interrupts > 0 {
+		f.interrupts--
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(os.Interrupt)
+		}
+		if err != nil {
+			f.t.Errorf("sending SIGINT: %v", err)
+		}
+	}
+	line, ok := <-f.lines
+	if !ok {
+		return 0, io.EOF
+	}
+	return copy(p, line), nil
+}
+
+// newInterruptConsole returns a console that reads src the way it reads a
+// terminal
+func newInterruptConsole(src io.Reader, out io.Writer) *Console {
+	c := NewConsole(strings.NewReader(""), out)
+	c.intr = &interruptReader{src: src}
+	c.dec = &decodeReader{r: c.intr}
+	c.in = bufio.NewReader(c.dec)
+	return c
+}
+
+func TestInterruptReader(t *testing.T) {
+	tty := newFakeTTY(t, 1)
+	r := &interruptReader{src: tty}
+	release := r.trap()
+	defer release()
+
+	buf := make([]byte, 2)
+	if n, err := r.Read(buf); n != 0 || err != ErrInterrupted {
+		t.Fatalf("Read during Ctrl-C = %d, %v; want ErrInterrupted", n, err)
+	}
+
+	// The abandoned read is still outstanding and delivers the next line
+	tty.lines <- "abc"
+	var got []byte
+	for len(got) < 3 {
+		n, err := r.Read(buf)
+		if err != nil {
+			t.Fatalf("Read after Ctrl-C: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "abc" {
+		t.Errorf("Read after Ctrl-C = %q; want %q", got, "abc")
+	}
+
+	close(tty.lines)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(buf); err != io.EOF {
+			t.Errorf("Read at the end of input = %v; want io.EOF", err)
+		}
+	}
+}
+
+func TestReadLineInterrupted(t *testing.T) {
+	tty := newFakeTTY(t, 1)
+	defer close(tty.lines)
+	var out bytes.Buffer
+	c := newInterruptConsole(tty, &out)
+
+	_, err := c.ReadLine("Name: ")
+	if err != ErrInterrupted || !errors.Is(err, ErrAborted) {
+		t.Fatalf("ReadLine after Ctrl-C: err = %v; want ErrInterrupted", err)
+	}
+	if out.String() != "Name: \n" {
+		t.Errorf("output = %q; the line should end after the prompt", out.String())
+	}
+
+	// Input typed after Ctrl-C is read by the next prompt
+	tty.lines <- "Ada\n"
+	if name, err := c.ReadLine("Name: "); err != nil || name != "Ada" {
+		t.Errorf("ReadLine after Ctrl-C = %q, %v; want %q", name, err, "Ada")
+	}
+}
+
+func TestOnInterruptResumes(t *testing.T) {
+	tty := newFakeTTY(t, 1)
+	defer close(tty.lines)
+	c := newInterruptConsole(tty, io.Discard)
+
+	calls := 0
+	OnInterrupt(func() bool {
+		calls++
+		tty.lines <- "Ada\n"
+		return true
+	})
+	defer OnInterrupt(nil)
+
+	name, err := c.ReadLine("Name: ")
+	if err != nil || name != "Ada" {
+		t.Errorf("ReadLine resumed by OnInterrupt = %q, %v; want %q", name, err, "Ada")
+	}
+	if calls != 1 {
+		t.Errorf("OnInterrupt hook ran %d times; want 1", calls)
+	}
+}
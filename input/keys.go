@@ -0,0 +1,148 @@
+package input
+
+import (
+	"bufio"
+	"unicode/utf8"
+)
+
+// keyKind identifies a key read from the terminal in raw mode
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyPageUp
+	keyPageDown
+	keyEnter
+	keySpace
+	keyTab
+	keyBackspace
+	keyEscape
+	keyCtrlC
+	keyCtrlD
+	keyCtrlU
+	keyPasteStart // bracketed paste begins
+	keyPasteEnd   // bracketed paste ends
+	keyUnknown
+)
+
+// keyEvent is a single key press; r is set for keyRune
+type keyEvent struct {
+	kind keyKind
+	r    rune
+}
+
+// readKey reads one key press from r, decoding the escape sequences sent by
+// common terminals for arrows, paging and home/end
+func readKey(r *bufio.Reader) (keyEvent, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return keyEvent{}, err
+	}
+
+	switch b {
+	case '\r', '\n':
+		return keyEvent{kind: keyEnter}, nil
+	case ' ':
+		return keyEvent{kind: keySpace, r: ' '}, nil
+	case '\t':
+		return keyEvent{kind: keyTab}, nil
+	case 127, 8:
+		return keyEvent{kind: keyBackspace}, nil
+	case 3:
+		return keyEvent{kind: keyCtrlC}, nil
+	case 4:
+		return keyEvent{kind: keyCtrlD}, nil
+	case 21:
+		return keyEvent{kind: keyCtrlU}, nil
+	case 14: // Ctrl-N
+		return keyEvent{kind: keyDown}, nil
+	case 16: // Ctrl-P
+		return keyEvent{kind: keyUp}, nil
+	case 27:
+		return readEscape(r)
+	}
+
+	if b < 32 {
+		return keyEvent{kind: keyUnknown}, nil
+	}
+	if b < utf8.RuneSelf {
+		return keyEvent{kind: keyRune, r: rune(b)}, nil
+	}
+
+	// Multi-byte UTF-8 character
+	if err := r.UnreadByte(); err != nil {
+		return keyEvent{}, err
+	}
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return keyEvent{}, err
+	}
+	return keyEvent{kind: keyRune, r: ch}, nil
+}
+
+// readEscape decodes the rest of an escape sequence. A lone Escape key is
+// recognized by the absence of further buffered bytes.
+func readEscape(r *bufio.Reader) (keyEvent, error) {
+	if r.Buffered() == 0 {
+		return keyEvent{kind: keyEscape}, nil
+	}
+	b, err := r.ReadByte()
+	if err != nil {
+		return keyEvent{}, err
+	}
+	if b != '[' && b != 'O' {
+		return keyEvent{kind: keyUnknown}, nil
+	}
+
+	// Collect parameter bytes up to the final byte of the sequence
+	var params []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return keyEvent{}, err
+		}
+		if c >= 0x40 && c <= 0x7e {
+			return decodeCSI(params, c), nil
+		}
+		params = append(params, c)
+	}
+}
+
+func decodeCSI(params []byte, final byte) keyEvent {
+	switch final {
+	case 'A':
+		return keyEvent{kind: keyUp}
+	case 'B':
+		return keyEvent{kind: keyDown}
+	case 'C':
+		return keyEvent{kind: keyRight}
+	case 'D':
+		return keyEvent{kind: keyLeft}
+	case 'H':
+		return keyEvent{kind: keyHome}
+	case 'F':
+		return keyEvent{kind: keyEnd}
+	case '~':
+		switch string(params) {
+		case "1", "7":
+			return keyEvent{kind: keyHome}
+		case "4", "8":
+			return keyEvent{kind: keyEnd}
+		case "5":
+			return keyEvent{kind: keyPageUp}
+		case "6":
+			return keyEvent{kind: keyPageDown}
+		case "200":
+			return keyEvent{kind: keyPasteStart}
+		case "201":
+			return keyEvent{kind: keyPasteEnd}
+		}
+	}
+	return keyEvent{kind: keyUnknown}
+}
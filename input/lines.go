@@ -0,0 +1,207 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/grandpaej/fmtpy/v2/internal/term"
+)
+
+// LinesOption configures InputLines
+type LinesOption func(*linesConfig)
+
+type linesConfig struct {
+	sentinel    string
+	hasSentinel bool
+	untilBlank  bool
+	numbers     bool
+	cont        string
+}
+
+// UntilLine ends the input at a line that is exactly sentinel, ignoring
+// surrounding whitespace. The sentinel line is not part of the result.
+//
+//	_, log, err := input.InputLines("Paste the log, then EOF:\n", input.UntilLine("EOF"))
+func UntilLine(sentinel string) LinesOption {
+	return func(c *linesConfig) {
+		c.sentinel, c.hasSentinel = strings.TrimSpace(sentinel), true
+	}
+}
+
+// UntilBlank ends the input at the first empty line, which is not part of
+// the result
+func UntilBlank() LinesOption {
+	return func(c *linesConfig) {
+		c.untilBlank = true
+	}
+}
+
+// LineNumbers shows a line-number gutter before each line
+func LineNumbers() LinesOption {
+	return func(c *linesConfig) {
+		c.numbers = true
+	}
+}
+
+// ContinuationPrompt is written before every line after the first, like the
+// "... " of the Python REPL
+func ContinuationPrompt(prompt string) LinesOption {
+	return func(c *linesConfig) {
+		c.cont = prompt
+	}
+}
+
+// InputLines reads several lines of text, such as a paragraph or a pasted
+// block. Input always ends at Ctrl-D or the end of input; UntilLine and
+// UntilBlank add a sentinel or an empty line as terminators. On a terminal,
+// pasted text is recognized (bracketed paste), so newlines inside a paste
+// never end the input. It returns io.EOF only when input ended before any
+// line was read, and ErrInterrupted on Ctrl-C. The lines are returned both as
+// a slice and joined with newlines.
+//
+//	lines, text, err := input.InputLines(">>> ", input.ContinuationPrompt("... "), input.UntilBlank())
+func InputLines(prompt string, opts ...LinesOption) (lines []string, text string, err error) {
+	cfg := &linesConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	c := DefaultConsole()
+	for {
+		if c.interactive(cfg.linePrompt(prompt, len(lines))) {
+			lines, err = readLinesRaw(c, prompt, cfg, lines)
+		} else {
+			lines, err = readLines(c, prompt, cfg, lines)
+		}
+		// After the OnInterrupt hook resumes, continue below the lines so far
+		if err != ErrInterrupted || !resumeAfterInterrupt() {
+			break
+		}
+	}
+	return lines, strings.Join(lines, "\n"), err
+}
+
+// readLines reads lines from input that is not a terminal, appending them
+// to lines
+func readLines(c *Console, prompt string, cfg *linesConfig, lines []string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.trapInterrupts()()
+
+	for {
+		line, err := c.readLine(cfg.linePrompt(prompt, len(lines)), false)
+		if err == io.EOF {
+			if lines == nil {
+				return nil, io.EOF
+			}
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+		if cfg.ends(line) {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// linePrompt returns the text written before line n, counting from 0
+func (cfg *linesConfig) linePrompt(prompt string, n int) string {
+	if n > 0 {
+		prompt = cfg.cont
+	}
+	if cfg.numbers {
+		prompt += fmt.Sprintf("%3d │ ", n+1)
+	}
+	return prompt
+}
+
+// ends reports whether line terminates the input
+func (cfg *linesConfig) ends(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return (cfg.hasSentinel && trimmed == cfg.sentinel) || (cfg.untilBlank && trimmed == "")
+}
+
+// readLinesRaw reads lines in raw mode with bracketed paste enabled, echoing
+// input itself and appending to lines. Only the current line can be edited.
+func readLinesRaw(c *Console, prompt string, cfg *linesConfig, lines []string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	defer c.trapInterrupts()()
+
+	fd := c.inFile.Fd()
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		// Keep the lines read before an interrupt resumed this call
+		return lines, err
+	}
+	defer term.Restore(fd, state)
+
+	out := bufio.NewWriter(c.out)
+	fmt.Fprint(out, "\033[?2004h")
+	defer func() {
+		fmt.Fprint(out, "\033[?2004l")
+		out.Flush()
+	}()
+
+	var line []rune
+	pasting := false
+	fmt.Fprint(out, cfg.linePrompt(prompt, len(lines)))
+	for {
+		out.Flush()
+		k, err := readKey(c.in)
+		if err != nil {
+			fmt.Fprint(out, "\r\n")
+			if err == io.EOF && lines != nil {
+				return lines, nil
+			}
+			return lines, err
+		}
+
+		switch k.kind {
+		case keyRune, keySpace:
+			line = append(line, k.r)
+			fmt.Fprint(out, string(k.r))
+		case keyTab:
+			line = append(line, '\t')
+			fmt.Fprint(out, "\t")
+		case keyBackspace:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				fmt.Fprintf(out, "\r\033[K%s%s", cfg.linePrompt(prompt, len(lines)), string(line))
+			}
+		case keyCtrlU:
+			line = line[:0]
+			fmt.Fprintf(out, "\r\033[K%s", cfg.linePrompt(prompt, len(lines)))
+		case keyPasteStart:
+			pasting = true
+		case keyPasteEnd:
+			pasting = false
+		case keyCtrlC:
+			fmt.Fprint(out, "\r\n")
+			return lines, ErrInterrupted
+		case keyCtrlD:
+			if len(line) > 0 {
+				continue
+			}
+			fmt.Fprint(out, "\r\n")
+			if lines == nil {
+				return nil, io.EOF
+			}
+			return lines, nil
+		case keyEnter:
+			fmt.Fprint(out, "\r\n")
+			text := string(line)
+			line = line[:0]
+			if !pasting && cfg.ends(text) {
+				return lines, nil
+			}
+			lines = append(lines, text)
+			fmt.Fprint(out, cfg.linePrompt(prompt, len(lines)))
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// Locale describes how numbers are written in a region
+type Locale struct {
+	Name     string
+	Decimal  string // decimal separator, e.g. "," in de_DE
+	Group    string // thousands separator, empty for none
+	Grouping []int  // group sizes from the right; the last size repeats. Indian lakh grouping is {3, 2}
+
+	Currency       string // currency symbol
+	CurrencyDigits int    // digits after the decimal separator for currency amounts
+	CurrencyBefore bool   // symbol before the amount ($5) rather than after (5 €)
+	CurrencySpace  bool   // space between symbol and amount
+	PercentBefore  bool   // percent sign before the number (%25)
+	PercentSpace   bool   // space between number and percent sign (25 %)
+}
+
+const (
+	nbsp  = " " // no-break space
+	nnbsp = " " // narrow no-break space
+)
+
+// locales holds the built-in locale tables, keyed by language_REGION
+var locales = map[string]*Locale{
+	"C":     {Name: "C", Decimal: ".", CurrencyDigits: 2},
+	"en_US": {Name: "en_US", Decimal: ".", Group: ",", Grouping: []int{3}, Currency: "$", CurrencyDigits: 2, CurrencyBefore: true},
+	"en_GB": {Name: "en_GB", Decimal: ".", Group: ",", Grouping: []int{3}, Currency: "£", CurrencyDigits: 2, CurrencyBefore: true},
+	"en_IN": {Name: "en_IN", Decimal: ".", Group: ",", Grouping: []int{3, 2}, Currency: "₹", CurrencyDigits: 2, CurrencyBefore: true},
+	"hi_IN": {Name: "hi_IN", Decimal: ".", Group: ",", Grouping: []int{3, 2}, Currency: "₹", CurrencyDigits: 2, CurrencyBefore: true},
+	"de_DE": {Name: "de_DE", Decimal: ",", Group: ".", Grouping: []int{3}, Currency: "€", CurrencyDigits: 2, CurrencySpace: true, PercentSpace: true},
+	"de_CH": {Name: "de_CH", Decimal: ".", Group: "’", Grouping: []int{3}, Currency: "CHF", CurrencyDigits: 2, CurrencyBefore: true, CurrencySpace: true},
+	"fr_FR": {Name: "fr_FR", Decimal: ",", Group: nnbsp, Grouping: []int{3}, Currency: "€", CurrencyDigits: 2, CurrencySpace: true, PercentSpace: true},
+	"es_ES": {Name: "es_ES", Decimal: ",", Group: ".", Grouping: []int{3}, Currency: "€", CurrencyDigits: 2, CurrencySpace: true, PercentSpace: true},
+	"it_IT": {Name: "it_IT", Decimal: ",", Group: ".", Grouping: []int{3}, Currency: "€", CurrencyDigits: 2, CurrencySpace: true},
+	"nl_NL": {Name: "nl_NL", Decimal: ",", Group: ".", Grouping: []int{3}, Currency: "€", CurrencyDigits: 2, CurrencyBefore: true, CurrencySpace: true},
+	"pt_BR": {Name: "pt_BR", Decimal: ",", Group: ".", Grouping: []int{3}, Currency: "R$", CurrencyDigits: 2, CurrencyBefore: true, CurrencySpace: true},
+	"pt_PT": {Name: "pt_PT", Decimal: ",", Group: nbsp, Grouping: []int{3}, Currency: "€", CurrencyDigits: 2, CurrencySpace: true},
+	"ru_RU": {Name: "ru_RU", Decimal: ",", Group: nbsp, Grouping: []int{3}, Currency: "₽", CurrencyDigits: 2, CurrencySpace: true, PercentSpace: true},
+	"pl_PL": {Name: "pl_PL", Decimal: ",", Group: nbsp, Grouping: []int{3}, Currency: "zł", CurrencyDigits: 2, CurrencySpace: true},
+	"sv_SE": {Name: "sv_SE", Decimal: ",", Group: nbsp, Grouping: []int{3}, Currency: "kr", CurrencyDigits: 2, CurrencySpace: true, PercentSpace: true},
+	"tr_TR": {Name: "tr_TR", Decimal: ",", Group: ".", Grouping: []int{3}, Currency: "₺", CurrencyDigits: 2, CurrencyBefore: true, PercentBefore: true},
+	"ja_JP": {Name: "ja_JP", Decimal: ".", Group: ",", Grouping: []int{3}, Currency: "¥", CurrencyDigits: 0, CurrencyBefore: true},
+	"zh_CN": {Name: "zh_CN", Decimal: ".", Group: ",", Grouping: []int{3}, Currency: "¥", CurrencyDigits: 2, CurrencyBefore: true},
+	"ko_KR": {Name: "ko_KR", Decimal: ".", Group: ",", Grouping: []int{3}, Currency: "₩", CurrencyDigits: 0, CurrencyBefore: true},
+}
+
+// languageDefaults maps a bare language code to its most common locale
+var languageDefaults = map[string]string{
+	"en": "en_US", "hi": "hi_IN", "de": "de_DE", "fr": "fr_FR", "es": "es_ES",
+	"it": "it_IT", "nl": "nl_NL", "pt": "pt_BR", "ru": "ru_RU", "pl": "pl_PL",
+	"sv": "sv_SE", "tr": "tr_TR", "ja": "ja_JP", "zh": "zh_CN", "ko": "ko_KR",
+}
+
+// selectedLocale is set by SetLocale; nil means detect from the environment
+var selectedLocale atomic.Pointer[Locale]
+
+// LookupLocale finds a built-in locale by name. Encodings and modifiers are
+// ignored and a bare language picks its most common region, so "de",
+// "de-DE" and "de_DE.UTF-8" all find de_DE.
+func LookupLocale(name string) (*Locale, bool) {
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.Replace(name, "-", "_", 1)
+	if name == "POSIX" {
+		name = "C"
+	}
+	if l, ok := locales[name]; ok {
+		return l, true
+	}
+
+	lang, region, _ := strings.Cut(name, "_")
+	lang = strings.ToLower(lang)
+	if l, ok := locales[lang+"_"+strings.ToUpper(region)]; ok {
+		return l, true
+	}
+	if full, ok := languageDefaults[lang]; ok {
+		return locales[full], true
+	}
+	return nil, false
+}
+
+// SetLocale selects the locale used for number parsing and formatting.
+// An empty name goes back to detecting it from the environment. It is safe
+// to call while other goroutines parse or format numbers.
+func SetLocale(name string) error {
+	if name == "" {
+		selectedLocale.Store(nil)
+		return nil
+	}
+	l, ok := LookupLocale(name)
+	if !ok {
+		return fmt.Errorf("input: unknown locale %q", name)
+	}
+	selectedLocale.Store(l)
+	return nil
+}
+
+// CurrentLocale returns the locale set with SetLocale, or else the one named
+// by LC_ALL, LC_NUMERIC or LANG, in that order. Unknown or missing settings
+// give en_US.
+func CurrentLocale() *Locale {
+	if l := selectedLocale.Load(); l != nil {
+		return l
+	}
+	for _, key := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			if l, ok := LookupLocale(v); ok {
+				return l
+			}
+			break
+		}
+	}
+	return locales["en_US"]
+}
+
+// localeOrCurrent looks up name, falling back to the current locale
+func localeOrCurrent(name string) *Locale {
+	if name != "" {
+		if l, ok := LookupLocale(name); ok {
+			return l
+		}
+	}
+	return CurrentLocale()
+}
+
+// FormatNumber formats v with the separators of the named locale, using the
+// current locale when name is empty or unknown
+//
+//	input.FormatNumber(1234567.5, "de_DE")  // 1.234.567,5
+//	input.FormatNumber(1234567, "en_IN")    // 12,34,567
+func FormatNumber(v float64, locale string) string {
+	return localeOrCurrent(locale).Format(v, -1)
+}
+
+// ParseNumber converts text written in the named locale, such as "1.234,56"
+// for de_DE, to a float64. Text that does not follow the locale's grouping
+// is read with Python's float() rules instead, so "3.5" still works for a
+// German user. An empty name uses the current locale.
+//
+// Text that is valid in the locale always wins: under de_DE "1.234" is one
+// thousand two hundred thirty-four, not 1.234.
+func ParseNumber(s string, locale string) (float64, error) {
+	if f, err := localeOrCurrent(locale).Parse(s); err == nil {
+		return f, nil
+	}
+	return ParseFloat(s)
+}
+
+// Format writes v with prec digits after the decimal separator, or the
+// fewest digits needed to represent v when prec is negative
+func (l *Locale) Format(v float64, prec int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', prec, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(v), 'f', prec, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+
+	out := l.group(intPart)
+	if frac != "" {
+		out += l.Decimal + frac
+	}
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		out = "-" + out
+	}
+	return out
+}
+
+// FormatUint writes n with the locale's group separators. Unlike Format it
+// keeps every digit of integers too large for a float64.
+func (l *Locale) FormatUint(n uint64) string {
+	return l.group(strconv.FormatUint(n, 10))
+}
+
+// FormatPercent writes v as a percentage, so 0.25 becomes "25%" or "25 %"
+func (l *Locale) FormatPercent(v float64, prec int) string {
+	num := l.Format(v*100, prec)
+	switch {
+	case l.PercentBefore:
+		return "%" + num
+	case l.PercentSpace:
+		return num + nbsp + "%"
+	}
+	return num + "%"
+}
+
+// FormatCurrency writes v as an amount in the locale's currency
+func (l *Locale) FormatCurrency(v float64) string {
+	num := l.Format(math.Abs(v), l.CurrencyDigits)
+	space := ""
+	if l.CurrencySpace {
+		space = nbsp
+	}
+	out := num + space + l.Currency
+	if l.CurrencyBefore {
+		out = l.Currency + space + num
+	}
+	if v < 0 {
+		out = "-" + out
+	}
+	return out
+}
+
+// group inserts group separators into a string of digits
+func (l *Locale) group(digits string) string {
+	if l.Group == "" || len(l.Grouping) == 0 {
+		return digits
+	}
+	var parts []string
+	for k := 0; len(digits) > 0; k++ {
+		size := l.Grouping[min(k, len(l.Grouping)-1)]
+		if size <= 0 || size >= len(digits) {
+			parts = append(parts, digits)
+			break
+		}
+		parts = append(parts, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, l.Group)
+}
+
+// Parse converts text written in this locale to a float64. Group
+// separators must be in the right places; a currency symbol or percent
+// sign is ignored. Where the locale groups with a no-break space, an
+// ordinary space is accepted too.
+func (l *Locale) Parse(s string) (float64, error) {
+	invalid := fmt.Errorf("input: %q is not a number in locale %s", s, l.Name)
+
+	t := strings.TrimSpace(s)
+	if l.Currency != "" {
+		t = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(t, l.Currency), l.Currency))
+	}
+	t = strings.TrimSpace(strings.Trim(t, "%"))
+
+	neg := false
+	if strings.HasPrefix(t, "-") || strings.HasPrefix(t, "−") || strings.HasPrefix(t, "+") {
+		neg = !strings.HasPrefix(t, "+")
+		_, size := utf8.DecodeRuneInString(t)
+		t = t[size:]
+	}
+
+	intPart, frac := t, ""
+	if i := strings.Index(t, l.Decimal); i >= 0 {
+		intPart, frac = t[:i], t[i+len(l.Decimal):]
+	}
+
+	if seps := l.groupSeparators(); len(seps) > 0 {
+		var groups []string
+		for _, sep := range seps {
+			if strings.Contains(intPart, sep) {
+				groups = strings.Split(intPart, sep)
+				break
+			}
+		}
+		if groups != nil {
+			if !validGroups(groups, l.Grouping) {
+				return 0, invalid
+			}
+			intPart = strings.Join(groups, "")
+		}
+	}
+
+	if (intPart == "" && frac == "") || !allDigits(intPart) || !allDigits(frac) {
+		return 0, invalid
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	f, err := strconv.ParseFloat(intPart+"."+frac+"0", 64)
+	if err != nil {
+		return 0, invalid
+	}
+	if neg {
+		f = -f
+	}
+	return f, nil
+}
+
+// groupSeparators lists the separators accepted when parsing
+func (l *Locale) groupSeparators() []string {
+	switch l.Group {
+	case "":
+		return nil
+	case nbsp, nnbsp:
+		return []string{l.Group, " "}
+	case "’":
+		return []string{"’", "'"}
+	}
+	return []string{l.Group}
+}
+
+// validGroups checks digit groups, written left to right, against group
+// sizes counted from the right
+func validGroups(groups []string, sizes []int) bool {
+	if len(sizes) == 0 {
+		return false
+	}
+	for i, k := len(groups)-1, 0; i >= 0; i, k = i-1, k+1 {
+		size := sizes[min(k, len(sizes)-1)]
+		n := len(groups[i])
+		if n == 0 || !allDigits(groups[i]) {
+			return false
+		}
+		if i > 0 && n != size {
+			return false
+		}
+		if i == 0 && n > size {
+			return false
+		}
+	}
+	return true
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isASCIIDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
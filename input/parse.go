@@ -0,0 +1,197 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ParseInt converts s to an int the way Python's int(s, base) does:
+// surrounding whitespace is ignored, a leading + or - is allowed, digits may
+// be grouped with single underscores ("1_000"), and base 16, 8 and 2 accept
+// an optional 0x, 0o or 0b prefix. Base 0 picks the base from the prefix and
+// otherwise reads decimal without leading zeros.
+//
+//	input.ParseInt("0x1F", 0)   // 31
+//	input.ParseInt("ff", 16)    // 255
+//	input.ParseInt(" -1_000 ", 10) // -1000
+func ParseInt(s string, base int) (int, error) {
+	n, err := ParseBigInt(s, base)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() || n.Int64() < math.MinInt || n.Int64() > math.MaxInt {
+		return 0, fmt.Errorf("input: %q is out of range for int", strings.TrimSpace(s))
+	}
+	return int(n.Int64()), nil
+}
+
+// ParseBigInt is like ParseInt but without a size limit, matching Python's
+// arbitrary-precision integers
+func ParseBigInt(s string, base int) (*big.Int, error) {
+	if base != 0 && (base < 2 || base > 36) {
+		return nil, fmt.Errorf("input: int() base must be >= 2 and <= 36, or 0, got %d", base)
+	}
+	invalid := fmt.Errorf("input: invalid literal for int() with base %d: %q", base, s)
+
+	t := strings.TrimSpace(s)
+	neg := false
+	if t != "" && (t[0] == '+' || t[0] == '-') {
+		neg = t[0] == '-'
+		t = t[1:]
+	}
+
+	// An explicit prefix is removed when it agrees with base; a following
+	// underscore is then allowed, as in 0x_ff
+	prefixed := false
+	if len(t) >= 2 && t[0] == '0' {
+		prefixBase := 0
+		switch t[1] {
+		case 'x', 'X':
+			prefixBase = 16
+		case 'o', 'O':
+			prefixBase = 8
+		case 'b', 'B':
+			prefixBase = 2
+		}
+		if prefixBase != 0 && (base == 0 || base == prefixBase) {
+			base, t, prefixed = prefixBase, t[2:], true
+		}
+	}
+	decimalAuto := base == 0
+	if decimalAuto {
+		base = 10
+	}
+
+	digits, ok := stripUnderscores(t, prefixed)
+	if !ok || digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return nil, invalid
+	}
+	if decimalAuto && len(digits) > 1 && digits[0] == '0' && strings.Trim(digits, "0") != "" {
+		return nil, invalid // Python rejects "010" with base 0
+	}
+
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, invalid
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// stripUnderscores removes digit-grouping underscores, which must each sit
+// between two digits. A leading underscore is only allowed after a base
+// prefix.
+func stripUnderscores(s string, leadingOK bool) (string, bool) {
+	if !strings.Contains(s, "_") {
+		return s, true
+	}
+	if strings.HasSuffix(s, "_") || strings.Contains(s, "__") || (!leadingOK && strings.HasPrefix(s, "_")) {
+		return "", false
+	}
+	return strings.ReplaceAll(s, "_", ""), true
+}
+
+// ParseFloat converts s to a float64 the way Python's float(s) does:
+// surrounding whitespace is ignored, "inf", "infinity" and "nan" are
+// accepted in any case with an optional sign, and digits may be grouped
+// with single underscores. Hexadecimal floats are rejected, and values too
+// large for float64 become ±Inf instead of failing.
+func ParseFloat(s string) (float64, error) {
+	invalid := fmt.Errorf("input: could not convert string to float: %q", s)
+
+	t := strings.TrimSpace(s)
+	body := strings.TrimLeft(t, "+-")
+	if len(t)-len(body) > 1 {
+		return 0, invalid
+	}
+	switch strings.ToLower(body) {
+	case "inf", "infinity":
+		if strings.HasPrefix(t, "-") {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+
+	if strings.ContainsAny(body, "xXpP") {
+		return 0, invalid
+	}
+	if strings.Contains(body, "_") {
+		for i := 0; i < len(body); i++ {
+			if body[i] != '_' {
+				continue
+			}
+			if i == 0 || i == len(body)-1 || !isASCIIDigit(body[i-1]) || !isASCIIDigit(body[i+1]) {
+				return 0, invalid
+			}
+		}
+		t = strings.ReplaceAll(t, "_", "")
+	}
+
+	f, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+			return f, nil
+		}
+		return 0, invalid
+	}
+	return f, nil
+}
+
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// ToBigIntE converts s to an integer of any size, accepting everything a
+// person is likely to type: decimal with leading zeros ("007"), Python
+// literals with a 0x/0o/0b prefix or underscores, and whole-valued decimals
+// such as "3.0"
+func ToBigIntE(s string) (*big.Int, error) {
+	n, err := ParseBigInt(s, 10)
+	if err == nil {
+		return n, nil
+	}
+	if n, err := ParseBigInt(s, 0); err == nil {
+		return n, nil
+	}
+	if f, ferr := ParseFloat(s); ferr == nil && !math.IsInf(f, 0) && f == math.Trunc(f) {
+		n, _ := big.NewFloat(f).Int(nil)
+		return n, nil
+	}
+	return nil, err
+}
+
+// ToIntE converts s to an int like ToInt but reports why conversion failed
+func ToIntE(s string) (int, error) {
+	n, err := ToBigIntE(s)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() || n.Int64() < math.MinInt || n.Int64() > math.MaxInt {
+		return 0, fmt.Errorf("input: %q is out of range for int", strings.TrimSpace(s))
+	}
+	return int(n.Int64()), nil
+}
+
+// ToFloatE converts s to a float64 like ToFloat but reports why conversion failed
+func ToFloatE(s string) (float64, error) {
+	return ParseFloat(s)
+}
+
+// ToBoolE converts y/yes/true/1 to true and n/no/false/0 to false, ignoring
+// case and surrounding whitespace, and fails for anything else
+func ToBoolE(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "y", "yes", "true", "1":
+		return true, nil
+	case "n", "no", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("input: %q is not a yes/no value", s)
+}
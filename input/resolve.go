@@ -0,0 +1,269 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/grandpaej/fmtpy/v2/color"
+)
+
+// ErrNoValue is returned by Resolver.Get when no source supplied a value
+var ErrNoValue = errors.New("input: no value")
+
+// Source tells which source supplied a resolved value
+type Source int
+
+const (
+	SourceNone Source = iota
+	SourceFlag
+	SourceEnv
+	SourceConfig
+	SourcePrompt
+	SourceDefault
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceFlag:
+		return "flag"
+	case SourceEnv:
+		return "env"
+	case SourceConfig:
+		return "config"
+	case SourcePrompt:
+		return "prompt"
+	case SourceDefault:
+		return "default"
+	}
+	return "none"
+}
+
+// Resolver looks up a setting in several places, in this order: a
+// command-line flag, environment variables, a config file, a prompt and
+// finally a default. Create one with Value.
+type Resolver struct {
+	name       string
+	flags      *flag.FlagSet
+	envs       []string
+	config     map[string]string
+	configName string
+	configPath string // read by Get
+	def        *string
+	prompt     string
+
+	source Source
+	from   string
+}
+
+// Value starts resolving the setting called name. The name is also the flag
+// name and the config key.
+//
+//	name, err := input.Value("name").
+//	    Flag(flag.CommandLine).   // --name
+//	    Env("APP_NAME").
+//	    ConfigFile("app.json").   // {"name": "..."}
+//	    Prompt("Name: ").
+//	    Default("guest").
+//	    Get()
+func Value(name string) *Resolver {
+	return &Resolver{name: name}
+}
+
+// Flag takes the value from the flag with the resolver's name when it was
+// set on the command line. The flag set must be parsed before Get. A nil
+// set means flag.CommandLine.
+func (r *Resolver) Flag(set *flag.FlagSet) *Resolver {
+	if set == nil {
+		set = flag.CommandLine
+	}
+	r.flags = set
+	return r
+}
+
+// Env takes the value from the first of the environment variables that is
+// set and not empty
+func (r *Resolver) Env(names ...string) *Resolver {
+	r.envs = append(r.envs, names...)
+	return r
+}
+
+// Config takes the value from values, keyed by the resolver's name, such as
+// settings already loaded from a config file
+func (r *Resolver) Config(values map[string]string) *Resolver {
+	r.config, r.configName, r.configPath = values, "config", ""
+	return r
+}
+
+// ConfigFile takes the value from a JSON object in the file at path, keyed
+// by the resolver's name. The file is read by Get, and only when no flag or
+// environment variable has the value. A missing file is skipped; a
+// malformed one makes Get fail.
+func (r *Resolver) ConfigFile(path string) *Resolver {
+	r.config, r.configName, r.configPath = nil, path, path
+	return r
+}
+
+// readConfig reads a JSON config file into a map of strings. A missing file
+// gives an empty map.
+func readConfig(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("input: reading %s: %w", path, err)
+	}
+	config := make(map[string]string, len(raw))
+	for k, v := range raw {
+		config[k] = fmt.Sprint(v)
+	}
+	return config, nil
+}
+
+// Default is used when no other source has a value, including when the
+// user answers the prompt with an empty line or input has ended
+func (r *Resolver) Default(value string) *Resolver {
+	r.def = &value
+	return r
+}
+
+// Prompt asks the user when no flag, environment variable or config value
+// is found. The prompt goes through the default console, so it is skipped in
+// non-interactive mode.
+func (r *Resolver) Prompt(prompt string) *Resolver {
+	r.prompt = prompt
+	return r
+}
+
+// Source returns the source that supplied the value, after Get
+func (r *Resolver) Source() Source {
+	return r.source
+}
+
+// From describes where the value came from after Get, such as "flag --name",
+// "env APP_NAME" or "config app.json"
+func (r *Resolver) From() string {
+	if r.from == "" {
+		return r.source.String()
+	}
+	return r.source.String() + " " + r.from
+}
+
+// Get resolves the value. When no source has one, or prompting is not
+// possible in non-interactive mode, the error wraps ErrNoValue or
+// ErrNonInteractive and names the flag and variables that could be set.
+func (r *Resolver) Get() (InputValue, error) {
+	r.source, r.from = SourceNone, ""
+
+	if r.flags != nil && r.flags.Parsed() {
+		var value string
+		set := false
+		r.flags.Visit(func(f *flag.Flag) {
+			if f.Name == r.name {
+				value, set = f.Value.String(), true
+			}
+		})
+		if set {
+			return r.found(SourceFlag, "--"+r.name, value)
+		}
+	}
+	for _, env := range r.envs {
+		if value := os.Getenv(env); value != "" {
+			return r.found(SourceEnv, env, value)
+		}
+	}
+	config := r.config
+	if r.configPath != "" {
+		var err error
+		if config, err = readConfig(r.configPath); err != nil {
+			return "", err
+		}
+	}
+	if value, ok := config[r.name]; ok {
+		return r.found(SourceConfig, r.configName, value)
+	}
+
+	if r.prompt != "" {
+		value, err := r.ask()
+		if err == nil {
+			return value, nil
+		}
+		// Without a user to ask, fall back to the default
+		if (err != ErrNonInteractive && err != io.EOF) || r.def == nil {
+			return "", &valueError{name: r.name, hint: r.hint(), err: err}
+		}
+	}
+	if r.def != nil {
+		return r.found(SourceDefault, "", *r.def)
+	}
+	return "", &valueError{name: r.name, hint: r.hint(), err: ErrNoValue}
+}
+
+// valueError reports a setting that no source supplied
+type valueError struct {
+	name, hint string
+	err        error
+}
+
+func (e *valueError) Error() string {
+	msg := fmt.Sprintf("input: no value for %q%s", e.name, e.hint)
+	switch {
+	case e.err == ErrNonInteractive:
+		msg += " and input is non-interactive"
+	case e.err != ErrNoValue:
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+func (e *valueError) Unwrap() error {
+	return e.err
+}
+
+// ask prompts until the user gives a value, or an empty answer selects
+// the default
+func (r *Resolver) ask() (InputValue, error) {
+	console := DefaultConsole()
+	for {
+		answer, err := console.ReadLine(r.prompt)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case answer != "":
+			return r.found(SourcePrompt, "", answer)
+		case r.def != nil:
+			return r.found(SourceDefault, "", *r.def)
+		}
+		console.Println(color.Red("A value is required"))
+	}
+}
+
+func (r *Resolver) found(source Source, from, value string) (InputValue, error) {
+	r.source, r.from = source, from
+	return InputValue(value), nil
+}
+
+// hint lists the flag and environment variables that can supply the value
+func (r *Resolver) hint() string {
+	var ways []string
+	if r.flags != nil {
+		ways = append(ways, "--"+r.name)
+	}
+	ways = append(ways, r.envs...)
+	if len(ways) == 0 {
+		return ""
+	}
+	return " (set " + strings.Join(ways, " or ") + ")"
+}
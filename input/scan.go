@@ -0,0 +1,239 @@
+package input
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Fields splits the value on whitespace
+func (iv InputValue) Fields() []string {
+	return strings.Fields(string(iv))
+}
+
+// SplitBy splits the value on sep and trims whitespace around each part.
+// An empty value gives an empty slice rather than one empty part.
+func (iv InputValue) SplitBy(sep string) []string {
+	s := strings.TrimSpace(string(iv))
+	if s == "" {
+		return []string{}
+	}
+	parts := strings.Split(s, sep)
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+// Ints converts each whitespace-separated field to an int, like Python's
+// list(map(int, input().split()))
+func (iv InputValue) Ints() ([]int, error) {
+	fields := iv.Fields()
+	nums := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := ToIntE(f)
+		if err != nil {
+			return nil, fmt.Errorf("input: value %d (%q) is not an integer", i+1, f)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// Floats converts each whitespace-separated field to a float64
+func (iv InputValue) Floats() ([]float64, error) {
+	fields := iv.Fields()
+	nums := make([]float64, len(fields))
+	for i, f := range fields {
+		n, err := ToFloatE(f)
+		if err != nil {
+			return nil, fmt.Errorf("input: value %d (%q) is not a number", i+1, f)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// Scan splits the value on whitespace and stores each field in the matching
+// pointer, converting it to the pointed-to type. The number of fields must
+// equal the number of pointers.
+//
+//	var a, b int
+//	err := Input("Two numbers: ").Scan(&a, &b)   // like a, b = map(int, input().split())
+//
+// Supported pointer types are *string, *InputValue, *bool, *float32,
+// *float64 and all sized and unsized integer types.
+func (iv InputValue) Scan(ptrs ...interface{}) error {
+	fields := iv.Fields()
+	if len(fields) != len(ptrs) {
+		return fmt.Errorf("input: expected %d values, got %d", len(ptrs), len(fields))
+	}
+	for i, f := range fields {
+		if err := scanField(f, ptrs[i]); err != nil {
+			return fmt.Errorf("input: value %d (%q): %v", i+1, f, err)
+		}
+	}
+	return nil
+}
+
+// scanField converts s and stores it in ptr
+func scanField(s string, ptr interface{}) error {
+	var err error
+	switch p := ptr.(type) {
+	case *string:
+		*p = s
+	case *InputValue:
+		*p = InputValue(s)
+	case *bool:
+		*p, err = ToBoolE(s)
+	case *int:
+		var n int64
+		n, err = scanInt(s, strconv.IntSize)
+		*p = int(n)
+	case *int8:
+		var n int64
+		n, err = scanInt(s, 8)
+		*p = int8(n)
+	case *int16:
+		var n int64
+		n, err = scanInt(s, 16)
+		*p = int16(n)
+	case *int32:
+		var n int64
+		n, err = scanInt(s, 32)
+		*p = int32(n)
+	case *int64:
+		*p, err = scanInt(s, 64)
+	case *uint:
+		var n uint64
+		n, err = scanUint(s, strconv.IntSize)
+		*p = uint(n)
+	case *uint8:
+		var n uint64
+		n, err = scanUint(s, 8)
+		*p = uint8(n)
+	case *uint16:
+		var n uint64
+		n, err = scanUint(s, 16)
+		*p = uint16(n)
+	case *uint32:
+		var n uint64
+		n, err = scanUint(s, 32)
+		*p = uint32(n)
+	case *uint64:
+		*p, err = scanUint(s, 64)
+	case *float32:
+		var f float64
+		f, err = ToFloatE(s)
+		*p = float32(f)
+	case *float64:
+		*p, err = ToFloatE(s)
+	default:
+		return fmt.Errorf("unsupported type %T", ptr)
+	}
+	return err
+}
+
+// scanInt converts s to a signed integer that fits in bitSize bits
+func scanInt(s string, bitSize int) (int64, error) {
+	n, err := ToBigIntE(s)
+	if err != nil {
+		return 0, fmt.Errorf("not an integer")
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bitSize-1))
+	if n.CmpAbs(limit) > 0 || (n.Sign() > 0 && n.Cmp(limit) == 0) {
+		return 0, fmt.Errorf("out of range")
+	}
+	return n.Int64(), nil
+}
+
+// scanUint converts s to an unsigned integer that fits in bitSize bits
+func scanUint(s string, bitSize int) (uint64, error) {
+	n, err := ToBigIntE(s)
+	if err != nil {
+		return 0, fmt.Errorf("not an integer")
+	}
+	if n.Sign() < 0 || n.BitLen() > bitSize {
+		return 0, fmt.Errorf("out of range")
+	}
+	return n.Uint64(), nil
+}
+
+// KeyValues parses whitespace-separated key=value pairs with shell-like
+// quoting, so values may contain spaces:
+//
+//	Input("Options: ").KeyValues()   // a=1 b="two words" c='x y' -> map[a:1 b:two words c:x y]
+//
+// Inside double quotes and outside quotes a backslash escapes the next
+// character; single quotes take everything literally.
+func (iv InputValue) KeyValues() (map[string]string, error) {
+	words, err := splitShell(string(iv))
+	if err != nil {
+		return nil, err
+	}
+	pairs := make(map[string]string, len(words))
+	for _, w := range words {
+		eq := strings.Index(w, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("input: %q is not a key=value pair", w)
+		}
+		pairs[w[:eq]] = w[eq+1:]
+	}
+	return pairs, nil
+}
+
+// splitShell splits s into words the way a POSIX shell would, handling
+// single quotes, double quotes and backslash escapes
+func splitShell(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("input: unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
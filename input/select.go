@@ -0,0 +1,333 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grandpaej/fmtpy/v2/color"
+)
+
+// ErrNoOptions is returned by Select and MultiSelect when there is nothing to choose from
+var ErrNoOptions = errors.New("input: no options to select from")
+
+// ErrAborted is returned when the user cancels an interactive prompt with Escape or Ctrl-D
+var ErrAborted = errors.New("input: prompt aborted")
+
+// defaultPageSize is the number of options shown at once before paging
+const defaultPageSize = 10
+
+// Select shows a menu of options and returns the index of the chosen one.
+// On a terminal the user moves with the arrow keys, types to filter and
+// presses Enter to choose. When stdin or stdout is not a terminal a numbered
+// list is printed and the user enters a number instead. The menu needs raw
+// terminal mode, available on Linux, macOS, FreeBSD, NetBSD and DragonFly;
+// on Windows and other systems the numbered list is always used.
+//
+//	i, err := input.Select("Pick a color:", []string{"red", "green", "blue"})
+func Select(prompt string, options []string) (int, error) {
+	if len(options) == 0 {
+		return -1, ErrNoOptions
+	}
+	c := DefaultConsole()
+	if !c.interactive(numberPrompt(len(options))) {
+		return selectFallback(c, prompt, options)
+	}
+
+	m := newMenu(c, prompt, options, false)
+	if err := runInteractive(c, m); err != nil {
+		return -1, err
+	}
+	return m.visible[m.cursor], nil
+}
+
+// MultiSelect shows a menu of options and returns the indexes of the chosen
+// ones in their original order. Space toggles the highlighted option and
+// Enter confirms. Without a terminal the user enters numbers such as "1,3"
+// or ranges such as "2-4".
+func MultiSelect(prompt string, options []string) ([]int, error) {
+	if len(options) == 0 {
+		return nil, ErrNoOptions
+	}
+	c := DefaultConsole()
+	if !c.interactive(numbersPrompt) {
+		return multiSelectFallback(c, prompt, options)
+	}
+
+	m := newMenu(c, prompt, options, true)
+	if err := runInteractive(c, m); err != nil {
+		return nil, err
+	}
+	chosen := make([]int, 0, len(m.selected))
+	for i := range options {
+		if m.selected[i] {
+			chosen = append(chosen, i)
+		}
+	}
+	return chosen, nil
+}
+
+// menu holds the state of an interactive Select or MultiSelect prompt
+type menu struct {
+	prompt   string
+	options  []string
+	lowered  []string
+	multi    bool
+	filter   []rune
+	visible  []int // indexes of options matching the filter
+	cursor   int   // position of the highlight within visible
+	offset   int   // first row of visible shown on the current page
+	selected map[int]bool
+	pageSize int
+	width    int
+}
+
+func newMenu(c *Console, prompt string, options []string, multi bool) *menu {
+	m := &menu{
+		prompt:   prompt,
+		options:  options,
+		lowered:  make([]string, len(options)),
+		multi:    multi,
+		selected: make(map[int]bool),
+		pageSize: defaultPageSize,
+	}
+	for i, opt := range options {
+		m.lowered[i] = strings.ToLower(opt)
+	}
+	m.width, m.pageSize = c.pageLayout(defaultPageSize, 3)
+	m.applyFilter()
+	return m
+}
+
+// handle applies a key press and reports whether the prompt is finished
+func (m *menu) handle(k keyEvent) (bool, error) {
+	switch k.kind {
+	case keyUp:
+		m.move(-1, true)
+	case keyDown, keyTab:
+		m.move(1, true)
+	case keyPageUp:
+		m.move(-m.pageSize, false)
+	case keyPageDown:
+		m.move(m.pageSize, false)
+	case keyHome:
+		m.move(-len(m.visible), false)
+	case keyEnd:
+		m.move(len(m.visible), false)
+	case keySpace:
+		if m.multi {
+			if len(m.visible) > 0 {
+				i := m.visible[m.cursor]
+				m.selected[i] = !m.selected[i]
+			}
+			break
+		}
+		m.filter = append(m.filter, ' ')
+		m.applyFilter()
+	case keyRune:
+		m.filter = append(m.filter, k.r)
+		m.applyFilter()
+	case keyBackspace:
+		if len(m.filter) > 0 {
+			m.filter = m.filter[:len(m.filter)-1]
+			m.applyFilter()
+		}
+	case keyCtrlU:
+		m.filter = m.filter[:0]
+		m.applyFilter()
+	case keyEnter:
+		if m.multi || len(m.visible) > 0 {
+			return true, nil
+		}
+	case keyCtrlC:
+		return false, ErrInterrupted
+	case keyEscape, keyCtrlD:
+		return false, ErrAborted
+	}
+	return false, nil
+}
+
+// move shifts the highlight by delta rows, wrapping around the ends when
+// wrap is set and clamping otherwise
+func (m *menu) move(delta int, wrap bool) {
+	n := len(m.visible)
+	if n == 0 {
+		return
+	}
+	m.cursor += delta
+	if wrap {
+		m.cursor = ((m.cursor % n) + n) % n
+	} else if m.cursor < 0 {
+		m.cursor = 0
+	} else if m.cursor >= n {
+		m.cursor = n - 1
+	}
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+m.pageSize {
+		m.offset = m.cursor - m.pageSize + 1
+	}
+}
+
+// applyFilter recomputes the visible options from the filter text
+func (m *menu) applyFilter() {
+	needle := strings.ToLower(string(m.filter))
+	m.visible = m.visible[:0]
+	for i, opt := range m.lowered {
+		if strings.Contains(opt, needle) {
+			m.visible = append(m.visible, i)
+		}
+	}
+	m.cursor = 0
+	m.offset = 0
+}
+
+// render returns the prompt, the current page of options and a paging footer
+func (m *menu) render() []string {
+	var b strings.Builder
+	b.WriteString(color.BoldCyan("? ") + m.prompt + " ")
+	if len(m.filter) > 0 {
+		b.WriteString(color.Yellow(string(m.filter)))
+	} else if m.multi {
+		b.WriteString(color.New(color.Faint).Sprint("(space to toggle, enter to confirm, type to filter)"))
+	} else {
+		b.WriteString(color.New(color.Faint).Sprint("(arrows to move, enter to select, type to filter)"))
+	}
+	lines := []string{b.String()}
+
+	if len(m.visible) == 0 {
+		lines = append(lines, color.New(color.Faint).Sprint("  no matches"))
+	}
+	end := m.offset + m.pageSize
+	if end > len(m.visible) {
+		end = len(m.visible)
+	}
+	for row := m.offset; row < end; row++ {
+		i := m.visible[row]
+		lines = append(lines, m.renderOption(i, row == m.cursor))
+	}
+	if len(m.visible) > m.pageSize {
+		footer := fmt.Sprintf("  (%d-%d of %d)", m.offset+1, end, len(m.visible))
+		lines = append(lines, color.New(color.Faint).Sprint(footer))
+	}
+
+	return lines
+}
+
+func (m *menu) renderOption(i int, current bool) string {
+	text := clip(m.options[i], m.width-8)
+
+	mark := ""
+	if m.multi {
+		mark = "[ ] "
+		if m.selected[i] {
+			mark = color.Green("[x]") + " "
+		}
+	}
+
+	if current {
+		return color.Cyan("> ") + mark + color.BoldCyan(text)
+	}
+	if m.multi && m.selected[i] {
+		return "  " + mark + color.Green(text)
+	}
+	return "  " + mark + text
+}
+
+// title returns the prompt text
+func (m *menu) title() string {
+	return m.prompt
+}
+
+// summary describes the final choice for the line left behind on screen
+func (m *menu) summary() string {
+	if !m.multi {
+		return m.options[m.visible[m.cursor]]
+	}
+	var chosen []string
+	for i, opt := range m.options {
+		if m.selected[i] {
+			chosen = append(chosen, opt)
+		}
+	}
+	return strings.Join(chosen, ", ")
+}
+
+// numbersPrompt asks for the choices of the MultiSelect fallback
+const numbersPrompt = "Enter numbers separated by commas (e.g. 1,3 or 2-4): "
+
+// numberPrompt asks for the choice of the Select fallback among n options
+func numberPrompt(n int) string {
+	return fmt.Sprintf("Enter a number (1-%d): ", n)
+}
+
+// selectFallback prints a numbered list and reads the choice as a number
+func selectFallback(c *Console, prompt string, options []string) (int, error) {
+	printNumbered(c, prompt, options)
+	for {
+		line, err := c.ReadLine(numberPrompt(len(options)))
+		if err != nil {
+			return -1, err
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		c.Println(color.Red(fmt.Sprintf("Please enter a number between 1 and %d", len(options))))
+	}
+}
+
+// multiSelectFallback prints a numbered list and reads a list of numbers
+// and ranges such as "1,3 5-7". An empty answer selects nothing.
+func multiSelectFallback(c *Console, prompt string, options []string) ([]int, error) {
+	printNumbered(c, prompt, options)
+	for {
+		line, err := c.ReadLine(numbersPrompt)
+		if err != nil {
+			return nil, err
+		}
+		chosen, err := parseSelection(line, len(options))
+		if err == nil {
+			return chosen, nil
+		}
+		c.Println(color.Red(err.Error()))
+	}
+}
+
+func printNumbered(c *Console, prompt string, options []string) {
+	c.Println(prompt)
+	width := len(strconv.Itoa(len(options)))
+	for i, opt := range options {
+		c.Printf("  %*d) %s\n", width, i+1, opt)
+	}
+}
+
+// parseSelection parses a list of 1-based numbers and ranges into sorted,
+// de-duplicated 0-based indexes
+func parseSelection(s string, n int) ([]int, error) {
+	picked := make([]bool, n)
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	for _, f := range fields {
+		lo, hi := f, f
+		if dash := strings.Index(f, "-"); dash > 0 {
+			lo, hi = f[:dash], f[dash+1:]
+		}
+		a, errA := strconv.Atoi(lo)
+		b, errB := strconv.Atoi(hi)
+		if errA != nil || errB != nil || a < 1 || b > n || a > b {
+			return nil, fmt.Errorf("%q is not a number or range between 1 and %d", f, n)
+		}
+		for i := a; i <= b; i++ {
+			picked[i-1] = true
+		}
+	}
+
+	chosen := []int{}
+	for i, ok := range picked {
+		if ok {
+			chosen = append(chosen, i)
+		}
+	}
+	return chosen, nil
+}
@@ -0,0 +1,97 @@
+package input
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+const (
+	keysUp       = "\x1b[A"
+	keysDown     = "\x1b[B"
+	keysPageUp   = "\x1b[5~"
+	keysPageDown = "\x1b[6~"
+	keysHome     = "\x1b[H"
+	keysEnd      = "\x1b[F"
+)
+
+// press feeds keys, as a terminal sends them, to m until they run out or
+// the menu is finished
+func press(t *testing.T, m *menu, keys string) (done bool, err error) {
+	t.Helper()
+	r := bufio.NewReader(strings.NewReader(keys))
+	for {
+		k, err := readKey(r)
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			t.Fatalf("readKey(%q): %v", keys, err)
+		}
+		if done, err := m.handle(k); done || err != nil {
+			return done, err
+		}
+	}
+}
+
+func TestMenuKeys(t *testing.T) {
+	fruit := []string{"apple", "banana", "cherry", "date", "elderberry", "fig", "grape", "honeydew"}
+	newTestMenu := func(multi bool) *menu {
+		m := newMenu(NewConsole(strings.NewReader(""), io.Discard), "Pick:", fruit, multi)
+		m.pageSize = 3
+		return m
+	}
+
+	m := newTestMenu(false)
+	for _, tt := range []struct {
+		name, keys     string
+		cursor, offset int
+	}{
+		{"up wraps to the end", keysUp, 7, 5},
+		{"down wraps to the start", keysDown, 0, 0},
+		{"down scrolls the page", keysDown + keysDown + keysDown, 3, 1},
+		{"ctrl-p moves up", "\x10", 2, 1},
+		{"page down", keysPageDown, 5, 3},
+		{"page down stops at the end", keysPageDown + keysPageDown, 7, 5},
+		{"page up", keysPageUp, 4, 4},
+		{"home", keysHome, 0, 0},
+		{"end", keysEnd, 7, 5},
+		{"typing filters", "er", 0, 0},
+		{"tab moves down", "\t", 1, 0},
+	} {
+		if done, err := press(t, m, tt.keys); done || err != nil {
+			t.Fatalf("%s: done = %v, err = %v", tt.name, done, err)
+		}
+		if m.cursor != tt.cursor || m.offset != tt.offset {
+			t.Errorf("%s: cursor, offset = %d, %d; want %d, %d", tt.name, m.cursor, m.offset, tt.cursor, tt.offset)
+		}
+	}
+	if done, err := press(t, m, "\r"); !done || err != nil || fruit[m.visible[m.cursor]] != "elderberry" {
+		t.Errorf("Enter on the filtered menu = %v, %v, %q; want elderberry", done, err, fruit[m.visible[m.cursor]])
+	}
+
+	// Enter does nothing when the filter hides every option
+	m = newTestMenu(false)
+	if done, err := press(t, m, "zzz\r"); done || err != nil {
+		t.Errorf("Enter with no matches = %v, %v; want the menu to stay open", done, err)
+	}
+	if done, err := press(t, m, "\x7f\x7f\x7f"+keysDown+"\r"); !done || err != nil || m.visible[m.cursor] != 1 {
+		t.Errorf("Enter after clearing the filter = %v, %v, %d; want banana", done, err, m.visible[m.cursor])
+	}
+
+	for keys, want := range map[string]error{"\x1b": ErrAborted, "\x04": ErrAborted, "\x03": ErrInterrupted} {
+		if done, err := press(t, newTestMenu(false), keys); done || err != want {
+			t.Errorf("press(%q) = %v, %v; want %v", keys, done, err, want)
+		}
+	}
+
+	// In a multi-select menu space toggles the highlighted option
+	m = newTestMenu(true)
+	if done, err := press(t, m, " "+keysDown+keysDown+" "+keysUp+" "+keysUp+" \r"); !done || err != nil {
+		t.Fatalf("multi-select: done = %v, err = %v", done, err)
+	}
+	if len(m.selected) != 3 || !m.selected[1] || !m.selected[2] || m.selected[0] {
+		t.Errorf("multi-select selected %v; want banana and cherry", m.selected)
+	}
+}
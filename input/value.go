@@ -0,0 +1,102 @@
+package input
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InputValue is text read from the user that can convert to different types.
+// fmtpy.Input returns it and fmtpy.InputValue is an alias for it.
+type InputValue string
+
+// String returns the string value
+func (iv InputValue) String() string {
+	return string(iv)
+}
+
+// Int converts to integer, returns 0 if conversion fails.
+// Python literals such as "1_000", "0x1F" and "+5" are accepted.
+func (iv InputValue) Int() int {
+	return ToInt(string(iv))
+}
+
+// Int64 converts to int64, returns 0 if conversion fails
+func (iv InputValue) Int64() int64 {
+	n, err := ToBigIntE(string(iv))
+	if err != nil || !n.IsInt64() {
+		return 0
+	}
+	return n.Int64()
+}
+
+// Uint converts to a non-negative integer, returns 0 if conversion fails
+func (iv InputValue) Uint() uint {
+	n, err := ToBigIntE(string(iv))
+	if err != nil || !n.IsUint64() || n.Uint64() > math.MaxUint {
+		return 0
+	}
+	return uint(n.Uint64())
+}
+
+// BigInt converts to an integer of any size, returns 0 if conversion fails
+func (iv InputValue) BigInt() *big.Int {
+	n, err := ToBigIntE(string(iv))
+	if err != nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+// Float converts to float64, returns 0.0 if conversion fails.
+// Numbers written for the current locale ("1.234,56" for de_DE) and Python
+// spellings such as "1_000.5", "inf" and "nan" are accepted.
+//
+// The locale is tried first, so where "." groups thousands a dot is not a
+// decimal point: under de_DE "1.234" is 1234, while "1.5" and "1.2345" are
+// still read as decimals because they are not valid German grouping. Call
+// input.SetLocale("C") or use ParseFloat for locale-independent input.
+func (iv InputValue) Float() float64 {
+	f, err := ParseNumber(string(iv), "")
+	if err != nil {
+		return 0.0
+	}
+	return f
+}
+
+// Complex converts to complex128 using Python's notation ("1+2j", "3j",
+// "(1-2j)"); Go's "1+2i" also works. Returns 0 if conversion fails.
+func (iv InputValue) Complex() complex128 {
+	s := strings.TrimSpace(string(iv))
+	if strings.HasSuffix(s, "j") || strings.HasSuffix(s, "J") {
+		s = s[:len(s)-1] + "i"
+	} else if strings.HasSuffix(s, "j)") || strings.HasSuffix(s, "J)") {
+		s = s[:len(s)-2] + "i)"
+	}
+	c, err := strconv.ParseComplex(s, 128)
+	if err != nil {
+		return 0
+	}
+	return c
+}
+
+// Duration converts to time.Duration using Go's notation ("1h30m", "250ms").
+// A plain number is read as seconds. Returns 0 if conversion fails.
+func (iv InputValue) Duration() time.Duration {
+	s := strings.TrimSpace(string(iv))
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	if f, err := ParseFloat(s); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return time.Duration(f * float64(time.Second))
+	}
+	return 0
+}
+
+// Bool converts to boolean (y/yes/true/1 = true, everything else = false)
+func (iv InputValue) Bool() bool {
+	s := strings.ToLower(strings.TrimSpace(string(iv)))
+	return s == "y" || s == "yes" || s == "true" || s == "1"
+}
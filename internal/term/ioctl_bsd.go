@@ -0,0 +1,11 @@
+//go:build darwin || freebsd || netbsd || dragonfly
+
+package term
+
+import "syscall"
+
+// ioctl requests that read and write the terminal settings
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
@@ -0,0 +1,9 @@
+package term
+
+import "syscall"
+
+// ioctl requests that read and write the terminal settings
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
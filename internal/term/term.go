@@ -0,0 +1,10 @@
+// Package term provides the small amount of terminal control needed by
+// fmtpy's interactive prompts: TTY detection, raw mode, window size and
+// opening the controlling terminal.
+package term
+
+import "errors"
+
+// ErrNotTerminal is returned when a terminal operation is attempted on a
+// file descriptor that is not attached to a terminal
+var ErrNotTerminal = errors.New("term: not a terminal")
@@ -0,0 +1,38 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !dragonfly
+
+package term
+
+import "os"
+
+// State holds the terminal settings to restore after raw mode
+type State struct{}
+
+// Supported reports whether terminal detection works on this platform
+const Supported = false
+
+// IsTerminal reports whether fd refers to a terminal. Terminal control is
+// implemented on Linux, macOS, FreeBSD, NetBSD and DragonFly; elsewhere
+// prompts use their line-based fallback.
+func IsTerminal(fd uintptr) bool {
+	return false
+}
+
+// MakeRaw is not supported on this platform
+func MakeRaw(fd uintptr) (*State, error) {
+	return nil, ErrNotTerminal
+}
+
+// Restore is a no-op on this platform
+func Restore(fd uintptr, s *State) error {
+	return nil
+}
+
+// Size is not supported on this platform
+func Size(fd uintptr) (width, height int, err error) {
+	return 0, 0, ErrNotTerminal
+}
+
+// OpenTTY is not supported on this platform
+func OpenTTY() (*os.File, error) {
+	return nil, ErrNotTerminal
+}
@@ -0,0 +1,92 @@
+//go:build linux || darwin || freebsd || netbsd || dragonfly
+
+package term
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// State holds the terminal settings to restore after raw mode
+type State struct {
+	termios syscall.Termios
+}
+
+func getTermios(fd uintptr) (*syscall.Termios, error) {
+	t := &syscall.Termios{}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlGetTermios, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return nil, errno
+	}
+	return t, nil
+}
+
+func setTermios(fd uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlSetTermios, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Supported reports whether terminal detection works on this platform
+const Supported = true
+
+// IsTerminal reports whether fd refers to a terminal
+func IsTerminal(fd uintptr) bool {
+	_, err := getTermios(fd)
+	return err == nil
+}
+
+// MakeRaw switches the terminal to character-at-a-time input without echo
+// and returns the previous state so it can be restored. Signal generation is
+// turned off too: Ctrl-C arrives as byte 0x03 instead of SIGINT, so the
+// caller can restore the terminal before giving up, and Ctrl-Z and Ctrl-\
+// cannot stop the process while the terminal is raw.
+func MakeRaw(fd uintptr) (*State, error) {
+	t, err := getTermios(fd)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	old := &State{termios: *t}
+
+	t.Iflag &^= syscall.ICRNL | syscall.IXON
+	t.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.IEXTEN | syscall.ISIG
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+	if err := setTermios(fd, t); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// Restore puts the terminal back into a state returned by MakeRaw
+func Restore(fd uintptr, s *State) error {
+	if s == nil {
+		return nil
+	}
+	return setTermios(fd, &s.termios)
+}
+
+// Size returns the width and height of the terminal
+func Size(fd uintptr) (width, height int, err error) {
+	var ws struct {
+		Row, Col, X, Y uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0, 0, errno
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// OpenTTY opens the controlling terminal for reading and writing, even when
+// stdin and stdout are redirected
+func OpenTTY() (*os.File, error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	return f, nil
+}